@@ -0,0 +1,463 @@
+// Package arb specifies functions and structs for writing dictionaries to Application Resource
+// Bundle (.arb) files of Flutter projects, one file per language, and reading them back
+package arb
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	// FilePrefix defines the prefix of names of ARB files, e.g. "app_en.arb"
+	FilePrefix = "app_"
+	// Extension defines the extension of ARB files
+	Extension = ".arb"
+	// LocaleKey defines the key of the locale of ARB file
+	LocaleKey = "@@locale"
+)
+
+// Options defines the options of writing and reading ARB files
+type Options struct {
+	// BaseLanguage defines the language code of source strings
+	BaseLanguage string
+	// SourceLanguage defines the BCP-47 tag of the base language, by default
+	// it is made of the base language or general.DefaultSourceLanguage is used
+	SourceLanguage string
+}
+
+// arbLocale returns the locale of the given language in ARB files, e.g. "pt_BR"
+func arbLocale(langCode string, opts Options) string {
+	tag := general.SourceLanguage(opts.SourceLanguage, opts.BaseLanguage)
+	if langCode != opts.BaseLanguage {
+		if l, ok := general.ParseLocale(langCode); ok {
+			tag = l.BCP47()
+		} else {
+			tag = langCode
+		}
+	}
+	return strings.Replace(tag, "-", "_", -1)
+}
+
+// field defines a single field of JSON object
+type field struct {
+	key   string
+	value interface{}
+}
+
+// object defines JSON object, that keeps the order of its fields
+type object []field
+
+// marshal encodes the given value to JSON without escaping of html
+func marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// MarshalJSON encodes the object with fields in their order
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decodeObject decodes JSON object and returns its keys in the order of the data
+func decodeObject(data []byte) (keys []string, values map[string]json.RawMessage, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if t, err := decoder.Token(); err != nil || t != json.Delim('{') {
+		return nil, nil, fmt.Errorf("expected JSON object")
+	}
+	values = map[string]json.RawMessage{}
+	for decoder.More() {
+		t, err := decoder.Token()
+		if err != nil {
+			return nil, nil, err
+		}
+		key, _ := t.(string)
+		var value json.RawMessage
+		if err = decoder.Decode(&value); err != nil {
+			return nil, nil, err
+		}
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+		}
+		values[key] = value
+	}
+	return keys, values, nil
+}
+
+// message defines a single message of ARB file, that is made of android string or plural
+type message struct {
+	key          string
+	plural       bool          // whether the message is made of items of plural
+	placeholders []placeholder // placeholders in the order of indexes of arguments
+	names        map[int]placeholder
+	selector     string // name of placeholder, that selects items of plural
+}
+
+// makeMessages returns messages of strings and plurals of the base language in its order, placeholders
+// are made of format specifiers of strings or "other" items of plurals, plurals get "count" placeholder
+// if they don't have integer argument, string arrays are skipped as ARB doesn't support them
+func makeMessages(base *general.Dictionary) (messages []*message) {
+	plurals := map[string]*message{}
+	for _, code := range base.Codes() {
+		if _, _, ok := general.ParseArrayCode(code); ok {
+			continue
+		}
+		name, _, plural := general.ParsePluralCode(code)
+		if !plural {
+			messages = append(messages, &message{key: code})
+			continue
+		}
+		if plurals[name] == nil {
+			plurals[name] = &message{key: name, plural: true}
+			messages = append(messages, plurals[name])
+		}
+	}
+
+	for _, m := range messages {
+		source := base.Get(m.key)
+		if m.plural {
+			source = base.Get(general.PluralCode(m.key, "other"))
+			for _, quantity := range general.PluralQuantities {
+				if value, ok := base.Lookup(general.PluralCode(m.key, quantity)); ok && source == "" {
+					source = value
+				}
+			}
+		}
+
+		names, indexes := argNames(source, m.plural)
+		m.names = names
+		for _, index := range indexes {
+			m.placeholders = append(m.placeholders, names[index])
+			if names[index].name == "count" {
+				m.selector = "count"
+			}
+		}
+		if m.plural && m.selector == "" {
+			m.selector = "count"
+			m.placeholders = append(m.placeholders, placeholder{name: "count", typ: "int"})
+		}
+	}
+	return messages
+}
+
+// value returns ARB message of the given dictionary, plurals are written as ICU plural expressions
+func (m *message) value(d *general.Dictionary) (value string, ok bool) {
+	if !m.plural {
+		if value, ok = d.Lookup(m.key); !ok {
+			return "", false
+		}
+		return toMessage(value, m.names), true
+	}
+
+	items := []pluralItem{}
+	for _, quantity := range general.PluralQuantities {
+		if value, ok := d.Lookup(general.PluralCode(m.key, quantity)); ok {
+			items = append(items, pluralItem{quantity: quantity, message: toMessage(value, m.names)})
+		}
+	}
+	if len(items) == 0 {
+		return "", false
+	}
+	return formatPlural(m.selector, items), true
+}
+
+// metadata returns "@key" block of the message with the given description and placeholders
+func (m *message) metadata(description string) (meta object) {
+	if description != "" {
+		meta = append(meta, field{"description", description})
+	}
+	if len(m.placeholders) > 0 {
+		placeholders := object{}
+		for _, ph := range m.placeholders {
+			attrs := object{{"type", ph.typ}}
+			if ph.example != "" {
+				attrs = append(attrs, field{"example", ph.example})
+			}
+			placeholders = append(placeholders, field{ph.name, attrs})
+		}
+		meta = append(meta, field{"placeholders", placeholders})
+	}
+	return meta
+}
+
+// writeFile writes the given ARB object to the file at the given path
+func writeFile(path string, arb object) (file *os.File, err error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(arb); err != nil {
+		return nil, err
+	}
+
+	return general.CreateFile(path, buf.Bytes())
+}
+
+// WriteARBFolder writes ARB file for each language of the given set of dictionaries to the folder at
+// the given path, files are named after locales, e.g. "app_pt_BR.arb", format specifiers are replaced
+// with placeholders named after ids of <xliff:g> spans or "argN", plurals are written as ICU plural
+// expressions, the file of the base language has "@key" blocks with descriptions and placeholders,
+// untranslatable strings are written to it only
+func WriteARBFolder(path string, dicts general.Dictionaries, opts Options) (files []*os.File, err error) {
+	err = os.MkdirAll(path, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	files = []*os.File{}
+	attrs := dicts[general.TranslatableMeta]
+	descriptions := dicts[general.DescriptionMeta]
+	messages := makeMessages(dicts[opts.BaseLanguage])
+
+	for _, langCode := range general.SortedLanguages(dicts, opts.BaseLanguage) {
+		if general.IsMeta(langCode) {
+			continue
+		}
+
+		locale := arbLocale(langCode, opts)
+		arb := object{{LocaleKey, locale}}
+		for _, m := range messages {
+			if langCode != opts.BaseLanguage && attrs.Get(m.key) == "false" {
+				continue
+			}
+			value, ok := m.value(dicts[langCode])
+			if !ok {
+				continue
+			}
+			arb = append(arb, field{m.key, value})
+			if langCode != opts.BaseLanguage {
+				continue
+			}
+			if meta := m.metadata(descriptions.Get(m.key)); len(meta) > 0 {
+				arb = append(arb, field{"@" + m.key, meta})
+			}
+		}
+
+		var file *os.File
+		file, err = writeFile(filepath.Join(path, FilePrefix+locale+Extension), arb)
+		if file != nil {
+			files = append(files, file)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return files, nil
+}
+
+// metadata defines "@key" block of ARB message
+type metadata struct {
+	description  string
+	placeholders []placeholder
+}
+
+// document defines the content of ARB file
+type document struct {
+	locale   string
+	keys     []string          // keys of messages in the order of the file
+	messages map[string]string // messages by keys
+	meta     map[string]metadata
+}
+
+// parseMetadata parses "@key" block of ARB message, placeholders are kept in the order of the file
+func parseMetadata(data []byte) (meta metadata, err error) {
+	var block struct {
+		Description  string          `json:"description"`
+		Placeholders json.RawMessage `json:"placeholders"`
+	}
+	if err = json.Unmarshal(data, &block); err != nil {
+		return metadata{}, err
+	}
+	meta.description = block.Description
+	if len(block.Placeholders) == 0 || string(block.Placeholders) == "null" {
+		return meta, nil
+	}
+
+	names, values, err := decodeObject(block.Placeholders)
+	if err != nil {
+		return metadata{}, err
+	}
+	for _, name := range names {
+		var attrs struct {
+			Type    string `json:"type"`
+			Example string `json:"example"`
+		}
+		if err = json.Unmarshal(values[name], &attrs); err != nil {
+			return metadata{}, err
+		}
+		meta.placeholders = append(meta.placeholders, placeholder{name: name, typ: attrs.Type, example: attrs.Example})
+	}
+	return meta, nil
+}
+
+// readDocument reads ARB file at the given path, the locale is taken from "@@locale"
+// key or from the name of file
+func readDocument(path string) (doc document, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return document{}, err
+	}
+	keys, values, err := decodeObject(bytes.TrimPrefix(data, []byte("\uFEFF")))
+	if err != nil {
+		return document{}, fmt.Errorf("%s: %v", path, err)
+	}
+
+	doc = document{messages: map[string]string{}, meta: map[string]metadata{}}
+	doc.locale = strings.TrimPrefix(strings.TrimSuffix(filepath.Base(path), Extension), FilePrefix)
+	for _, key := range keys {
+		switch {
+		case key == LocaleKey:
+			if err = json.Unmarshal(values[key], &doc.locale); err != nil {
+				return document{}, fmt.Errorf("%s: %s: %v", path, key, err)
+			}
+		case strings.HasPrefix(key, "@@"):
+			continue
+		case strings.HasPrefix(key, "@"):
+			if doc.meta[key[1:]], err = parseMetadata(values[key]); err != nil {
+				return document{}, fmt.Errorf("%s: %s: %v", path, key, err)
+			}
+		default:
+			var value string
+			if err = json.Unmarshal(values[key], &value); err != nil {
+				return document{}, fmt.Errorf("%s: %s: %v", path, key, err)
+			}
+			doc.keys = append(doc.keys, key)
+			doc.messages[key] = value
+		}
+	}
+	return doc, nil
+}
+
+// ReadARBFolder reads all ARB files in the folder at the given path and converts them to the set of
+// dictionaries, messages of the base language are stored under the base language code, other
+// languages are stored under android locale qualifiers, e.g. "pt-rBR", placeholders are converted
+// to format specifiers by their order in "@key" blocks, plural expressions are split to items
+// of plurals, descriptions of messages are stored in general.DescriptionMeta dictionary
+func ReadARBFolder(path string, opts Options) (dicts general.Dictionaries, err error) {
+	contents, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	srcLang := strings.Replace(general.SourceLanguage(opts.SourceLanguage, opts.BaseLanguage), "-", "_", -1)
+	docs := []document{}
+	for _, entry := range contents {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != Extension {
+			continue
+		}
+		doc, err := readDocument(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	// metadata is usually written to the file of the base language only, so it goes first
+	sort.SliceStable(docs, func(i, j int) bool { return docs[i].locale == srcLang && docs[j].locale != srcLang })
+
+	dicts = make(general.Dictionaries)
+	meta := map[string]metadata{}
+	for _, doc := range docs {
+		for key, m := range doc.meta {
+			if _, ok := meta[key]; !ok {
+				meta[key] = m
+			}
+		}
+	}
+
+	for _, doc := range docs {
+		langCode := opts.BaseLanguage
+		if doc.locale != srcLang {
+			l, ok := general.ParseBCP47(strings.Replace(doc.locale, "_", "-", -1))
+			if !ok {
+				continue
+			}
+			langCode = l.Qualifier()
+		}
+
+		for _, key := range doc.keys {
+			placeholders := meta[key].placeholders
+			if description := meta[key].description; description != "" && langCode == opts.BaseLanguage {
+				general.LanguageDictionary(dicts, general.DescriptionMeta).Set(key, description)
+			}
+
+			setMessage(general.LanguageDictionary(dicts, langCode), key, doc.messages[key], placeholders)
+		}
+	}
+
+	return dicts, nil
+}
+
+// setMessage converts ARB message with the given key and placeholders to android string or to
+// items of plurals and adds them to the given dictionary
+func setMessage(d *general.Dictionary, key string, msg string, placeholders []placeholder) {
+	selector, items, plural := parsePlural(msg)
+	if !plural {
+		d.Set(key, fromMessage(msg, placeholders))
+		return
+	}
+	if !hasPlaceholder(placeholders, selector) {
+		placeholders = append(append([]placeholder{}, placeholders...), placeholder{name: selector, typ: "int"})
+	}
+	for _, item := range items {
+		d.Set(general.PluralCode(key, item.quantity), fromMessage(item.message, placeholders))
+	}
+}
+
+// KeepMarkup replaces values of the given set of dictionaries, that differ from values of the
+// existing set only in <xliff:g> tags and format specifiers, that are changed on export to ARB
+// files, with the existing values (see general.KeepMarkup)
+func KeepMarkup(dicts general.Dictionaries, existing general.Dictionaries, opts Options) {
+	exported := make(general.Dictionaries)
+	for _, m := range makeMessages(existing[opts.BaseLanguage]) {
+		for langCode, d := range existing {
+			if general.IsMeta(langCode) {
+				continue
+			}
+			if value, ok := m.value(d); ok {
+				setMessage(general.LanguageDictionary(exported, langCode), m.key, value, m.placeholders)
+			}
+		}
+	}
+	general.KeepMarkup(dicts, existing, exported)
+}
+
+// hasPlaceholder reports whether the list of placeholders contains the one with the given name
+func hasPlaceholder(placeholders []placeholder, name string) bool {
+	for _, ph := range placeholders {
+		if ph.name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package arb
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWriteARB(t *testing.T) {
+	defer os.RemoveAll("/tmp/arb")
+	dicts := general.Dictionaries{
+		general.DefaultBaseLanguage: general.DictionaryOf(
+			"title", "<b>Title</b>",
+			"greeting", `Hi, <xliff:g id="name" example="Bob">%1$s</xliff:g>!`,
+			"apples#one", "%d apple",
+			"apples#other", "%d apples",
+			"planets[0]", "Mercury",
+			"app_name", "App",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"title", "<b>Título</b>",
+			"greeting", "Olá, %1$s!",
+			"apples#one", "%d maçã",
+			"apples#other", "%d maçãs",
+			"app_name", "Aplicativo",
+		),
+		general.DescriptionMeta: general.DictionaryOf(
+			"greeting", "Greeting on the main screen",
+		),
+		general.TranslatableMeta: general.DictionaryOf(
+			"app_name", "false",
+		),
+	}
+	_, err := WriteARBFolder("/tmp/arb", dicts, Options{BaseLanguage: general.DefaultBaseLanguage})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile("/tmp/arb/app_en.arb")
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "@@locale": "en",
+  "title": "<b>Title</b>",
+  "greeting": "Hi, {name}!",
+  "@greeting": {
+    "description": "Greeting on the main screen",
+    "placeholders": {
+      "name": {
+        "type": "String",
+        "example": "Bob"
+      }
+    }
+  },
+  "apples": "{count, plural, one{{count} apple} other{{count} apples}}",
+  "@apples": {
+    "placeholders": {
+      "count": {
+        "type": "int"
+      }
+    }
+  },
+  "app_name": "App"
+}
+`, string(data))
+
+	data, err = ioutil.ReadFile("/tmp/arb/app_pt_BR.arb")
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "@@locale": "pt_BR",
+  "title": "<b>Título</b>",
+  "greeting": "Olá, {name}!",
+  "apples": "{count, plural, one{{count} maçã} other{{count} maçãs}}"
+}
+`, string(data))
+
+	read, err := ReadARBFolder("/tmp/arb", Options{BaseLanguage: general.DefaultBaseLanguage})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		general.DefaultBaseLanguage: general.DictionaryOf(
+			"title", "<b>Title</b>",
+			"greeting", "Hi, %s!",
+			"apples#one", "%d apple",
+			"apples#other", "%d apples",
+			"app_name", "App",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"title", "<b>Título</b>",
+			"greeting", "Olá, %s!",
+			"apples#one", "%d maçã",
+			"apples#other", "%d maçãs",
+		),
+		general.DescriptionMeta: general.DictionaryOf(
+			"greeting", "Greeting on the main screen",
+		),
+	}, read)
+}
+
+func TestKeepMarkup(t *testing.T) {
+	dicts := general.Dictionaries{
+		"en": general.DictionaryOf(
+			"greeting", "Hi, %s!",
+			"apples#one", "%d apple",
+			"apples#other", "%d apples",
+			"title", "New title",
+		),
+		"de": general.DictionaryOf(
+			"greeting", "Hallo, %s!",
+		),
+	}
+	KeepMarkup(dicts, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"greeting", `Hi, <xliff:g id="name" example="Bob">%1$s</xliff:g>!`,
+			"apples#one", `<xliff:g id="count">%d</xliff:g> apple`,
+			"apples#other", `<xliff:g id="count">%d</xliff:g> apples`,
+			"title", "Title",
+		),
+		"de": general.DictionaryOf(
+			"greeting", `Hi, <xliff:g id="name">%1$s</xliff:g>!`,
+		),
+	}, Options{BaseLanguage: "en"})
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"greeting", `Hi, <xliff:g id="name" example="Bob">%1$s</xliff:g>!`,
+			"apples#one", `<xliff:g id="count">%d</xliff:g> apple`,
+			"apples#other", `<xliff:g id="count">%d</xliff:g> apples`,
+			"title", "New title",
+		),
+		"de": general.DictionaryOf(
+			"greeting", "Hallo, %s!",
+		),
+	}, dicts)
+}
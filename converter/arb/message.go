@@ -0,0 +1,264 @@
+package arb
+
+import (
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	// attrRe matches id and example attributes of <xliff:g> tag
+	attrRe = regexp.MustCompile(`\s(id|example)\s*=\s*["']([^"']*)["']`)
+	// nameRe matches valid names of placeholders
+	nameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+	// refRe matches references to placeholders in ARB messages, e.g. "{count}"
+	refRe = regexp.MustCompile(`\{\s*([A-Za-z][A-Za-z0-9_]*)\s*\}`)
+	// pluralRe matches the beginning of ICU plural expression, e.g. "{count, plural,"
+	pluralRe = regexp.MustCompile(`\{\s*([A-Za-z][A-Za-z0-9_]*)\s*,\s*plural\s*,`)
+)
+
+// spec defines a single format specifier of android string
+type spec struct {
+	index      int    // one-based index of argument
+	conversion string // conversion of argument, e.g. "s" or "d"
+	id         string // id of <xliff:g> span around the specifier
+	example    string // example of <xliff:g> span around the specifier
+}
+
+// placeholder defines a single placeholder of ARB message
+type placeholder struct {
+	name    string
+	typ     string // type of placeholder, e.g. "String" or "int"
+	example string
+}
+
+// replaceSpecs removes <xliff:g> tags of the given android string and replaces its format specifiers
+// with results of fn, "%%" is replaced with "%" and "%n" with new line
+func replaceSpecs(value string, fn func(spec) string) string {
+	var (
+		b          strings.Builder
+		span       spec // attributes of the current <xliff:g> span
+		next, last = 1, 0
+	)
+	replace := func(text string) {
+		b.WriteString(general.ReplaceFormatTokens(text, func(t general.FormatToken) string {
+			switch {
+			case !t.Android():
+				return text[t.Start:t.End]
+			case t.Conversion == "%":
+				return "%"
+			case t.Conversion == "n":
+				return "\n"
+			}
+			s := span
+			s.conversion = t.Conversion
+			switch {
+			case t.Index == "<":
+				s.index = last
+			case t.Index != "":
+				s.index, _ = strconv.Atoi(strings.TrimSuffix(t.Index, "$"))
+			default:
+				s.index = next
+				next++
+			}
+			last = s.index
+			return fn(s)
+		}))
+	}
+
+	pos := 0
+	for _, m := range general.XLIFFTagRe.FindAllStringIndex(value, -1) {
+		replace(value[pos:m[0]])
+		pos = m[1]
+		tag := value[m[0]:m[1]]
+
+		span = spec{}
+		if strings.HasPrefix(tag, "</") {
+			continue
+		}
+		for _, attr := range attrRe.FindAllStringSubmatch(tag, -1) {
+			if attr[1] == "id" {
+				span.id = attr[2]
+			} else {
+				span.example = attr[2]
+			}
+		}
+	}
+	replace(value[pos:])
+	return b.String()
+}
+
+// placeholderType returns the type of ARB placeholder for the given conversion
+func placeholderType(conversion string) string {
+	switch strings.ToLower(conversion) {
+	case "d", "x", "o":
+		return "int"
+	case "f", "e", "g", "a":
+		return "double"
+	case "s", "c":
+		return "String"
+	}
+	return "Object"
+}
+
+// conversion returns the conversion of android format specifier for the given type of placeholder
+func conversion(typ string) string {
+	switch typ {
+	case "int":
+		return "d"
+	case "double", "num":
+		return "f"
+	}
+	return "s"
+}
+
+// argNames returns names of placeholders by indexes of arguments of the given android string,
+// arguments are named after ids of <xliff:g> spans around them, the first integer argument of
+// plural is named "count", other arguments are named "argN", e.g. "arg2"
+func argNames(value string, plural bool) (names map[int]placeholder, indexes []int) {
+	names = map[int]placeholder{}
+	used := map[string]bool{}
+	replaceSpecs(value, func(s spec) string {
+		if _, ok := names[s.index]; ok {
+			return ""
+		}
+		name := "arg" + strconv.Itoa(s.index)
+		switch {
+		case nameRe.MatchString(s.id) && !used[s.id]:
+			name = s.id
+		case plural && s.index == 1 && placeholderType(s.conversion) == "int" && !used["count"]:
+			name = "count"
+		}
+		used[name] = true
+		names[s.index] = placeholder{name: name, typ: placeholderType(s.conversion), example: s.example}
+		indexes = append(indexes, s.index)
+		return ""
+	})
+	sort.Ints(indexes)
+	return names, indexes
+}
+
+// toMessage converts android string to ARB message, format specifiers are replaced
+// with references to placeholders with the given names, e.g. "{arg1}"
+func toMessage(value string, names map[int]placeholder) string {
+	return replaceSpecs(value, func(s spec) string {
+		if ph, ok := names[s.index]; ok {
+			return "{" + ph.name + "}"
+		}
+		return "{arg" + strconv.Itoa(s.index) + "}"
+	})
+}
+
+// fromMessage converts ARB message without plurals to android string, references to the given
+// placeholders are replaced with format specifiers, that are positional unless there is only
+// one placeholder, e.g. "%1$s" or "%d", percent signs are escaped if the message has references
+func fromMessage(message string, placeholders []placeholder) string {
+	specs := map[string]string{}
+	for i, ph := range placeholders {
+		if len(placeholders) == 1 {
+			specs[ph.name] = "%" + conversion(ph.typ)
+			continue
+		}
+		specs[ph.name] = "%" + strconv.Itoa(i+1) + "$" + conversion(ph.typ)
+	}
+
+	hasRefs := false
+	for _, m := range refRe.FindAllStringSubmatch(message, -1) {
+		if _, ok := specs[m[1]]; ok {
+			hasRefs = true
+		}
+	}
+	if !hasRefs {
+		return message
+	}
+
+	var b strings.Builder
+	pos := 0
+	for _, m := range refRe.FindAllStringSubmatchIndex(message, -1) {
+		s, ok := specs[message[m[2]:m[3]]]
+		if !ok {
+			continue
+		}
+		b.WriteString(strings.Replace(message[pos:m[0]], "%", "%%", -1) + s)
+		pos = m[1]
+	}
+	b.WriteString(strings.Replace(message[pos:], "%", "%%", -1))
+	return b.String()
+}
+
+// pluralItem defines a single case of ICU plural expression
+type pluralItem struct {
+	quantity string // quantity of android plural item, e.g. "one"
+	message  string
+}
+
+// formatPlural returns ICU plural expression with the given selector and items,
+// e.g. "{count, plural, one{{count} apple} other{{count} apples}}"
+func formatPlural(selector string, items []pluralItem) string {
+	cases := make([]string, 0, len(items))
+	for _, item := range items {
+		cases = append(cases, item.quantity+"{"+item.message+"}")
+	}
+	return "{" + selector + ", plural, " + strings.Join(cases, " ") + "}"
+}
+
+// matchBrace returns the position of the brace, that closes the brace at the given position, or -1
+func matchBrace(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// parsePlural parses the first ICU plural expression of the given message, the text around the
+// expression is added to each item, "#" signs of items and explicit cases "=0", "=1" and "=2" are
+// converted to the reference to the selector and to "zero", "one" and "two" quantities
+func parsePlural(message string) (selector string, items []pluralItem, ok bool) {
+	m := pluralRe.FindStringSubmatchIndex(message)
+	if m == nil {
+		return "", nil, false
+	}
+	end := matchBrace(message, m[0])
+	if end < 0 {
+		return "", nil, false
+	}
+	selector = message[m[2]:m[3]]
+	prefix, suffix := message[:m[0]], message[end+1:]
+
+	body := message[m[1]:end]
+	for pos := 0; ; {
+		open := strings.IndexByte(body[pos:], '{')
+		if open < 0 {
+			break
+		}
+		open += pos
+		closing := matchBrace(body, open)
+		if closing < 0 {
+			return "", nil, false
+		}
+		quantity := strings.TrimSpace(body[pos:open])
+		switch quantity {
+		case "=0":
+			quantity = "zero"
+		case "=1":
+			quantity = "one"
+		case "=2":
+			quantity = "two"
+		}
+		value := strings.Replace(body[open+1:closing], "#", "{"+selector+"}", -1)
+		items = append(items, pluralItem{quantity: quantity, message: prefix + value + suffix})
+		pos = closing + 1
+	}
+	return selector, items, true
+}
@@ -0,0 +1,60 @@
+package arb
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToMessage(t *testing.T) {
+	value := `Hi, <xliff:g id="name" example="Bob">%1$s</xliff:g>! You have %2$d new messages, 100%%`
+	names, indexes := argNames(value, false)
+	assert.Equal(t, []int{1, 2}, indexes)
+	assert.Equal(t, map[int]placeholder{
+		1: {name: "name", typ: "String", example: "Bob"},
+		2: {name: "arg2", typ: "int"},
+	}, names)
+	assert.Equal(t, "Hi, {name}! You have {arg2} new messages, 100%", toMessage(value, names))
+	assert.Equal(t, "Hallo, {arg2} und {name}", toMessage("Hallo, %2$d und %1$s", names))
+
+	names, indexes = argNames("100% sure, 20% Discount", false)
+	assert.Empty(t, names)
+	assert.Empty(t, indexes)
+	assert.Equal(t, "100% sure, 20% Discount", toMessage("100% sure, 20% Discount", names))
+
+	names, _ = argNames("%d apples", true)
+	assert.Equal(t, map[int]placeholder{1: {name: "count", typ: "int"}}, names)
+}
+
+func TestFromMessage(t *testing.T) {
+	placeholders := []placeholder{{name: "name", typ: "String"}, {name: "count", typ: "int"}}
+	assert.Equal(t, "Hi, %1$s! You have %2$d new messages, 100%%",
+		fromMessage("Hi, {name}! You have {count} new messages, 100%", placeholders))
+	assert.Equal(t, "100% {unknown}", fromMessage("100% {unknown}", placeholders))
+	assert.Equal(t, "%d apples", fromMessage("{count} apples", placeholders[1:]))
+	assert.Equal(t, "Total: %.2f", fromMessage("Total: %.2f", nil))
+}
+
+func TestPlural(t *testing.T) {
+	items := []pluralItem{{quantity: "one", message: "{count} apple"}, {quantity: "other", message: "{count} apples"}}
+	assert.Equal(t, "{count, plural, one{{count} apple} other{{count} apples}}", formatPlural("count", items))
+
+	selector, parsed, ok := parsePlural("{count, plural, one{{count} apple} other{{count} apples}}")
+	assert.True(t, ok)
+	assert.Equal(t, "count", selector)
+	assert.Equal(t, items, parsed)
+
+	selector, parsed, ok = parsePlural("You have {n, plural, =0{no apples} =1{one apple} other{# apples}}!")
+	assert.True(t, ok)
+	assert.Equal(t, "n", selector)
+	assert.Equal(t, []pluralItem{
+		{quantity: "zero", message: "You have no apples!"},
+		{quantity: "one", message: "You have one apple!"},
+		{quantity: "other", message: "You have {n} apples!"},
+	}, parsed)
+
+	_, _, ok = parsePlural("Hi, {name}!")
+	assert.False(t, ok)
+	_, _, ok = parsePlural("{count, plural, one{apple}")
+	assert.False(t, ok)
+}
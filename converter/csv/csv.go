@@ -4,6 +4,8 @@ package csv
 
 import (
 	"encoding/csv"
+	"errors"
+	"fmt"
 	"github.com/Semior001/androidstringstocsv/converter/general"
 	"os"
 )
@@ -35,7 +37,12 @@ func readSlicesFromCSVFile(path string) (vals [][]string, err error) {
 	if err != nil {
 		return nil, err
 	}
-	vals, err = csv.NewReader(file).ReadAll()
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	// rows with wrong number of columns are reported by convertSlicesToDictionaries
+	reader.FieldsPerRecord = -1
+	vals, err = reader.ReadAll()
 	return vals, err
 }
 
@@ -44,13 +51,22 @@ func readSlicesFromCSVFile(path string) (vals [][]string, err error) {
 //     name1,  val1,  val2,  val3 ...;
 //     name2,  val1,  val2,  val3 ...;
 //       ...,   ...,   ...,   ... ...
-func convertDictionariesToSlices(dicts general.Dictionaries) (vals [][]string) {
+//
+// Columns go in the stable order with the base language first, names go in the order of dictionaries,
+// translations missing in some language are left empty. Names of strings from multi-module projects
+// are written without paths of "res" folders, that are kept in the general.ModuleMeta column.
+func convertDictionariesToSlices(dicts general.Dictionaries, baseLang string) (vals [][]string) {
 	vals = [][]string{{SlicesHeader}}
 
-	// first filling out language codes and names
-	for langCode, dict := range dicts {
+	// first filling out language codes and names, each name goes only once
+	names := map[string]bool{}
+	for _, langCode := range general.SortedLanguages(dicts, baseLang) {
 		vals[0] = append(vals[0], langCode)
-		for name := range dict {
+		for _, name := range dicts[langCode].Codes() {
+			if names[name] {
+				continue
+			}
+			names[name] = true
 			vals = append(vals, []string{name})
 		}
 	}
@@ -59,8 +75,16 @@ func convertDictionariesToSlices(dicts general.Dictionaries) (vals [][]string) {
 		name := vals[i][0]
 		for j := 1; j < len(vals[0]); j++ {
 			langCode := vals[0][j]
-			vals[i] = append(vals[i], dicts[langCode][name])
+			vals[i] = append(vals[i], dicts[langCode].Get(name))
+		}
+	}
+
+	// paths of "res" folders are written to the module column, so names are left short
+	for i := 1; i < len(vals); i++ {
+		if dicts[general.ModuleMeta].Get(vals[i][0]) == "" {
+			continue
 		}
+		_, vals[i][0], _ = general.ParseModuleCode(vals[i][0])
 	}
 
 	return
@@ -71,29 +95,93 @@ func convertDictionariesToSlices(dicts general.Dictionaries) (vals [][]string) {
 //     name1,  val1,  val2,  val3 ...;
 //     name2,  val1,  val2,  val3 ...;
 //       ...,   ...,   ...,   ... ...
-func convertSlicesToDictionaries(vals [][]string) (dicts general.Dictionaries) {
+//
+// Empty cells are treated as missing translations, names are prefixed with paths of "res" folders
+// from the general.ModuleMeta column if there is one, rows and columns in errors are numbered from 1.
+func convertSlicesToDictionaries(vals [][]string) (dicts general.Dictionaries, err error) {
+	if len(vals) == 0 {
+		return nil, errors.New("missing header row")
+	}
+
 	dicts = make(general.Dictionaries)
 
 	// first filling out language codes
-	for _, langCode := range vals[0][1:] {
-		dicts[langCode] = make(general.Dictionary)
+	for j, langCode := range vals[0] {
+		if j == 0 {
+			continue
+		}
+		if langCode == "" {
+			return nil, fmt.Errorf("row 1, column %d: missing language code in header", j+1)
+		}
+		if _, exists := dicts[langCode]; exists {
+			return nil, fmt.Errorf("row 1, column %d: duplicated language code %q in header", j+1, langCode)
+		}
+		dicts[langCode] = general.NewDictionary()
+	}
+
+	moduleColumn := -1
+	for j, langCode := range vals[0] {
+		if j > 0 && langCode == general.ModuleMeta {
+			moduleColumn = j
+		}
 	}
 
 	for i := 1; i < len(vals); i++ {
+		if len(vals[i]) != len(vals[0]) {
+			return nil, fmt.Errorf("row %d: has %d columns, but header has %d", i+1, len(vals[i]), len(vals[0]))
+		}
+
+		name := vals[i][0]
+		if name == "" {
+			return nil, fmt.Errorf("row %d, column 1: missing string code", i+1)
+		}
+		if moduleColumn > 0 && vals[i][moduleColumn] != "" {
+			name = general.ModuleCode(vals[i][moduleColumn], name)
+		}
+
 		for j := 1; j < len(vals[i]); j++ {
-			langCode := vals[0][i]
-			name := vals[i][0]
+			langCode := vals[0][j]
 			val := vals[i][j]
+			if val == "" {
+				continue
+			}
 
-			dicts[langCode][name] = val
+			dicts[langCode].Set(name, val)
 		}
 	}
 
-	return
+	return dicts, nil
+}
+
+// Options defines the options of writing and reading csv file
+type Options struct {
+	// BaseLanguage defines the language code of base language, that is written as is
+	BaseLanguage string
+	// BCP47 defines whether to write language codes in header as BCP-47 tags,
+	// e.g. "pt-BR" instead of android locale qualifier "pt-rBR"
+	BCP47 bool
+}
+
+// headerLangCodes converts language codes in the header of the given matrix of strings with
+// the given function, codes of the base language and dictionaries with attributes are left as is
+func headerLangCodes(vals [][]string, baseLang string, convert func(general.Locale) string) {
+	if len(vals) == 0 {
+		return
+	}
+	for j := 1; j < len(vals[0]); j++ {
+		langCode := vals[0][j]
+		if langCode == baseLang || general.IsMeta(langCode) {
+			continue
+		}
+		if l, ok := general.ParseLocale(langCode); ok {
+			vals[0][j] = convert(l)
+		}
+	}
 }
 
-// WriteCSVFile writes the given set of dictionaries to the csv file
-func WriteCSVFile(path string, dicts general.Dictionaries) (file *os.File, err error) {
+// WriteCSVFile writes the given set of dictionaries to the csv file,
+// the column of the base language goes first
+func WriteCSVFile(path string, dicts general.Dictionaries, opts Options) (file *os.File, err error) {
 	// creating the csv file itself
 	file, err = os.Create(path)
 	if err != nil {
@@ -101,7 +189,10 @@ func WriteCSVFile(path string, dicts general.Dictionaries) (file *os.File, err e
 	}
 
 	csvWriter := csv.NewWriter(file)
-	vals := convertDictionariesToSlices(dicts)
+	vals := convertDictionariesToSlices(dicts, opts.BaseLanguage)
+	if opts.BCP47 {
+		headerLangCodes(vals, opts.BaseLanguage, general.Locale.BCP47)
+	}
 	err = csvWriter.WriteAll(vals)
 	if err != nil {
 		return
@@ -110,13 +201,18 @@ func WriteCSVFile(path string, dicts general.Dictionaries) (file *os.File, err e
 }
 
 // ReadCSVFile reads and unmarshals all words from the given csv file and converts to the
-// set of dictionaries
-func ReadCSVFile(path string) (dicts general.Dictionaries, err error) {
+// set of dictionaries, language codes in header may be written either as android locale
+// qualifiers or as BCP-47 tags, they are always converted to android locale qualifiers
+func ReadCSVFile(path string, opts Options) (dicts general.Dictionaries, err error) {
 	vals, err := readSlicesFromCSVFile(path)
 	if err != nil {
 		return
 	}
 
-	dicts = convertSlicesToDictionaries(vals)
+	headerLangCodes(vals, opts.BaseLanguage, general.Locale.Qualifier)
+	dicts, err = convertSlicesToDictionaries(vals)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
 	return
 }
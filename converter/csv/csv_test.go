@@ -4,34 +4,121 @@ import (
 	"os"
 	"testing"
 
+	"github.com/Semior001/androidstringstocsv/converter/general"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestConvertingDictToSlices(t *testing.T) {
-	vals := convertDictionariesToSlices(map[string]map[string]string{
-		"tl": map[string]string{
-			"test_str": "Test translation",
-		},
-	})
+	vals := convertDictionariesToSlices(general.Dictionaries{
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+		),
+	}, "en")
 	assert.Equal(t, [][]string{
 		{SlicesHeader, "tl"},
 		{"test_str", "Test translation"},
 	}, vals)
 }
 
+func TestConvertingMultipleDictsToSlices(t *testing.T) {
+	vals := convertDictionariesToSlices(general.Dictionaries{
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+			"tl_str", "Only tl translation",
+		),
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+			"en_str", "Only en string",
+		),
+		"de": general.DictionaryOf(
+			"en_str", "Nur de",
+			"test_str", "Test de",
+		),
+	}, "en")
+	assert.Equal(t, [][]string{
+		{SlicesHeader, "en", "de", "tl"},
+		{"test_str", "Test string", "Test de", "Test translation"},
+		{"en_str", "Only en string", "Nur de", ""},
+		{"tl_str", "", "", "Only tl translation"},
+	}, vals)
+}
+
 func TestVConvertingSlicesToDict(t *testing.T) {
-	dicts := convertSlicesToDictionaries([][]string{
+	dicts, err := convertSlicesToDictionaries([][]string{
 		{SlicesHeader, "tl"},
 		{"test_str", "Test translation"},
 	})
-	assert.Equal(t, map[string]map[string]string{
-		"tl": map[string]string{
-			"test_str": "Test translation",
-		},
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+		),
 	}, dicts)
 }
 
+func TestConvertingMultipleSlicesToDicts(t *testing.T) {
+	dicts, err := convertSlicesToDictionaries([][]string{
+		{SlicesHeader, "en", "de"},
+		{"test_str", "Test string", "Test de"},
+		{"en_str", "Only en string", ""},
+		{"other_str", "Other string", "Anderer"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+			"en_str", "Only en string",
+			"other_str", "Other string",
+		),
+		"de": general.DictionaryOf(
+			"test_str", "Test de",
+			"other_str", "Anderer",
+		),
+	}, dicts)
+}
+
+func TestConvertingInvalidSlicesToDicts(t *testing.T) {
+	tbl := []struct {
+		vals [][]string
+		err  string
+	}{
+		{[][]string{}, "missing header row"},
+		{[][]string{{SlicesHeader, "en", ""}}, "row 1, column 3: missing language code in header"},
+		{[][]string{{SlicesHeader, "en", "en"}}, `row 1, column 3: duplicated language code "en" in header`},
+		{[][]string{{SlicesHeader, "en"}, {"test_str", "Test", "Extra"}}, "row 2: has 3 columns, but header has 2"},
+		{[][]string{{SlicesHeader, "en", "de"}, {"test_str", "Test"}}, "row 2: has 2 columns, but header has 3"},
+		{[][]string{{SlicesHeader, "en"}, {"", "Test"}}, "row 2, column 1: missing string code"},
+	}
+	for _, tt := range tbl {
+		_, err := convertSlicesToDictionaries(tt.vals)
+		assert.EqualError(t, err, tt.err)
+	}
+}
+
+func TestConvertingModuleDicts(t *testing.T) {
+	dicts := general.Dictionaries{
+		"en": general.DictionaryOf(
+			"app/src/main/res:title", "App title",
+			"lib/src/main/res:title", "Lib title",
+		),
+		general.ModuleMeta: general.DictionaryOf(
+			"app/src/main/res:title", "app/src/main/res",
+			"lib/src/main/res:title", "lib/src/main/res",
+		),
+	}
+	vals := convertDictionariesToSlices(dicts, "en")
+	assert.Equal(t, [][]string{
+		{SlicesHeader, "en", general.ModuleMeta},
+		{"title", "App title", "app/src/main/res"},
+		{"title", "Lib title", "lib/src/main/res"},
+	}, vals)
+
+	readed, err := convertSlicesToDictionaries(vals)
+	require.NoError(t, err)
+	assert.Equal(t, dicts, readed)
+}
+
 func TestCSVReadWrite(t *testing.T) {
 	defer os.RemoveAll("/tmp/androidstringscsv.test")
 	_, err := writeSlicesToCSVFile("/tmp/androidstringscsv.test", [][]string{
@@ -51,18 +138,43 @@ func TestCSVReadWrite(t *testing.T) {
 
 func TestDictReadWrite(t *testing.T) {
 	defer os.RemoveAll("/tmp/androidstringscsv.test")
-	_, err := WriteCSVFile("/tmp/androidstringscsv.test", map[string]map[string]string{
-		"tl": map[string]string{
-			"test_str": "Test translation",
-		},
-	})
+	_, err := WriteCSVFile("/tmp/androidstringscsv.test", general.Dictionaries{
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+		),
+	}, Options{BaseLanguage: "en"})
 	require.NoError(t, err)
 	assert.FileExists(t, "/tmp/androidstringscsv.test", "function didn't create file")
 
-	dicts, err := ReadCSVFile("/tmp/androidstringscsv.test")
-	assert.Equal(t, map[string]map[string]string{
-		"tl": map[string]string{
-			"test_str": "Test translation",
-		},
+	dicts, err := ReadCSVFile("/tmp/androidstringscsv.test", Options{BaseLanguage: "en"})
+	assert.Equal(t, general.Dictionaries{
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+		),
 	}, dicts)
 }
+
+func TestBCP47ReadWrite(t *testing.T) {
+	defer os.RemoveAll("/tmp/androidstringscsv.test")
+	dicts := general.Dictionaries{
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"test_str", "Test pt",
+		),
+		"b+zh+Hant+TW": general.DictionaryOf(
+			"test_str", "Test zh",
+		),
+	}
+	_, err := WriteCSVFile("/tmp/androidstringscsv.test", dicts, Options{BaseLanguage: "en", BCP47: true})
+	require.NoError(t, err)
+
+	vals, err := readSlicesFromCSVFile("/tmp/androidstringscsv.test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{SlicesHeader, "en", "pt-BR", "zh-Hant-TW"}, vals[0])
+
+	readed, err := ReadCSVFile("/tmp/androidstringscsv.test", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, dicts, readed)
+}
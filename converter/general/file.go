@@ -0,0 +1,14 @@
+package general
+
+import "os"
+
+// CreateFile creates or truncates the file at the given path and writes the given data to it,
+// the returned file is open and has to be closed by the caller
+func CreateFile(path string, data []byte) (file *os.File, err error) {
+	file, err = os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	_, err = file.Write(data)
+	return file, err
+}
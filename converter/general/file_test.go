@@ -0,0 +1,28 @@
+package general
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "androidstringscsv")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "strings.txt")
+	require.NoError(t, ioutil.WriteFile(path, []byte("old longer content"), 0644))
+
+	file, err := CreateFile(path, []byte("new"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+}
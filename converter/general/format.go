@@ -0,0 +1,193 @@
+package general
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formatSpecRe matches the format specifier at the beginning of string, groups are: index, flags,
+// width and precision, length modifier and conversion, conversions are the ones of
+// java.util.Formatter and of iOS, e.g. "%s", "%1$d", "%.2f", "%tY", "%lu" or "%@"
+var formatSpecRe = regexp.MustCompile(`^%(\d+\$|<)?([-#+ 0,(]*)(\d*(?:\.\d+)?)(ll|l|hh|h|q|z|j)?([tT][a-zA-Z]|[bBhHsScCdiouxXeEfFgGaAnpDUO@%])`)
+
+// iosConversions defines conversions of format specifiers, that are not supported by
+// java.util.Formatter, so they are specific to iOS
+const iosConversions = "iupDUOF@"
+
+// FormatToken defines a single format specifier found in a string, including "%%" and "%n"
+type FormatToken struct {
+	Start      int    // offset of the specifier in the string
+	End        int    // offset right after the specifier
+	Index      string // explicit index of argument, e.g. "1$" or "<", empty if there is none
+	Flags      string // flags, e.g. "-" or "0"
+	Width      string // width and precision, e.g. "10" or ".2"
+	Length     string // length modifier of iOS specifiers, e.g. "l"
+	Conversion string // conversion, e.g. "s", "d", "tY", "@" or "%"
+}
+
+// Android reports whether the specifier is supported by java.util.Formatter,
+// specifiers with length modifiers or iOS conversions, e.g. "%lu" or "%@", are not
+func (t FormatToken) Android() bool {
+	return t.Length == "" && !strings.Contains(iosConversions, t.Conversion)
+}
+
+// FindFormatTokens returns all well-formed format specifiers of the given string in their order,
+// a space flag is accepted only with width or precision, e.g. "% 5d", so a percent sign
+// before a word, e.g. "100% sure", is not treated as a specifier
+func FindFormatTokens(s string) (tokens []FormatToken) {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '%' {
+			continue
+		}
+		m := formatSpecRe.FindStringSubmatch(s[i:])
+		if m == nil || (strings.Contains(m[2], " ") && m[3] == "") {
+			continue
+		}
+		tokens = append(tokens, FormatToken{
+			Start: i, End: i + len(m[0]),
+			Index: m[1], Flags: m[2], Width: m[3], Length: m[4], Conversion: m[5],
+		})
+		i += len(m[0]) - 1
+	}
+	return tokens
+}
+
+// ReplaceFormatTokens returns the copy of the given string with format specifiers
+// replaced with results of fn
+func ReplaceFormatTokens(s string, fn func(FormatToken) string) string {
+	var b strings.Builder
+	pos := 0
+	for _, t := range FindFormatTokens(s) {
+		b.WriteString(s[pos:t.Start])
+		b.WriteString(fn(t))
+		pos = t.End
+	}
+	b.WriteString(s[pos:])
+	return b.String()
+}
+
+// FormatSpec defines a single format specifier of string, e.g. "%1$s"
+type FormatSpec struct {
+	Index      int    // one-based index of argument
+	Conversion string // conversion of argument, e.g. "s", "d" or "tY"
+}
+
+// String returns the specifier in the positional form, e.g. "%1$s"
+func (s FormatSpec) String() string {
+	return "%" + strconv.Itoa(s.Index) + "$" + s.Conversion
+}
+
+// ParseFormatSpecs returns format specifiers of the given android string in the order of their
+// arguments, specifiers without explicit index take arguments one by one as in
+// java.util.Formatter, "%%" and "%n" are skipped as they don't take arguments
+func ParseFormatSpecs(s string) (specs []FormatSpec) {
+	next, last := 1, 0
+	for _, t := range FindFormatTokens(s) {
+		if !t.Android() || t.Conversion == "%" || t.Conversion == "n" {
+			continue
+		}
+
+		var index int
+		switch {
+		case t.Index == "<":
+			index = last
+		case t.Index != "":
+			index, _ = strconv.Atoi(strings.TrimSuffix(t.Index, "$"))
+		default:
+			index = next
+			next++
+		}
+		last = index
+
+		specs = append(specs, FormatSpec{Index: index, Conversion: t.Conversion})
+	}
+
+	sort.SliceStable(specs, func(i, j int) bool { return specs[i].Index < specs[j].Index })
+	return specs
+}
+
+// formatArgs returns conversions of format specifiers by indexes of their arguments
+// and the sorted list of indexes
+func formatArgs(specs []FormatSpec) (args map[int][]string, indexes []int) {
+	args = map[int][]string{}
+	for _, spec := range specs {
+		if _, ok := args[spec.Index]; !ok {
+			indexes = append(indexes, spec.Index)
+		}
+		args[spec.Index] = append(args[spec.Index], strings.ToLower(spec.Conversion))
+	}
+	sort.Ints(indexes)
+	return args, indexes
+}
+
+// CompareFormatSpecs compares format specifiers of the translation with the specifiers of source
+// string and describes the differences: missing and extra arguments and arguments, that are
+// formatted with other conversion, missing arguments are allowed if allowMissing is set
+func CompareFormatSpecs(source []FormatSpec, translation []FormatSpec, allowMissing bool) (problems []string) {
+	sourceArgs, sourceIndexes := formatArgs(source)
+	translationArgs, translationIndexes := formatArgs(translation)
+
+	for _, index := range sourceIndexes {
+		if _, ok := translationArgs[index]; !ok && !allowMissing {
+			problems = append(problems, "missing "+FormatSpec{Index: index, Conversion: sourceArgs[index][0]}.String())
+		}
+	}
+
+	for _, index := range translationIndexes {
+		conversions, ok := sourceArgs[index]
+		if !ok {
+			problems = append(problems, "extra "+FormatSpec{Index: index, Conversion: translationArgs[index][0]}.String())
+			continue
+		}
+		for _, conversion := range translationArgs[index] {
+			if !containsString(conversions, conversion) {
+				problems = append(problems, fmt.Sprintf("%s instead of %s",
+					FormatSpec{Index: index, Conversion: conversion}, FormatSpec{Index: index, Conversion: conversions[0]}))
+			}
+		}
+	}
+
+	return problems
+}
+
+// containsString reports whether the given slice contains the given string
+func containsString(ss []string, s string) bool {
+	for _, item := range ss {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateFormats compares format specifiers of translations in the given set of dictionaries with
+// specifiers of strings of the base language and returns the list of problems in the form
+// "language: code: problem", items of plurals are compared with the same item of the base language
+// or with its "other" item and may omit arguments, e.g. "One apple" for "%d apples"
+func ValidateFormats(dicts Dictionaries, baseLang string) (problems []string) {
+	base := dicts[baseLang]
+	for _, langCode := range SortedLanguages(dicts, baseLang) {
+		if langCode == baseLang || IsMeta(langCode) {
+			continue
+		}
+		d := dicts[langCode]
+		for _, code := range d.Codes() {
+			source, ok := base.Lookup(code)
+			name, _, plural := ParsePluralCode(code)
+			if !ok && plural {
+				source, ok = base.Lookup(PluralCode(name, "other"))
+			}
+			if !ok {
+				continue
+			}
+
+			for _, problem := range CompareFormatSpecs(ParseFormatSpecs(source), ParseFormatSpecs(d.Get(code)), plural) {
+				problems = append(problems, fmt.Sprintf("%s: %s: %s", langCode, code, problem))
+			}
+		}
+	}
+	return problems
+}
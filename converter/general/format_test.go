@@ -0,0 +1,87 @@
+package general
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormatSpecs(t *testing.T) {
+	tbl := []struct {
+		str   string
+		specs []FormatSpec
+	}{
+		{"Plain text", nil},
+		{"100%% sure%n", nil},
+		{"%s has %d apples", []FormatSpec{{1, "s"}, {2, "d"}}},
+		{"%2$d apples of %1$s", []FormatSpec{{1, "s"}, {2, "d"}}},
+		{"Price: %.2f, date: %1$tY-%<tm", []FormatSpec{{1, "f"}, {1, "tY"}, {1, "tm"}}},
+		{"%-10s|%05d", []FormatSpec{{1, "s"}, {2, "d"}}},
+		{"100% sure, 20% Discount", nil},
+		{"%@ has %lu apples", nil},
+		{"% 5d", []FormatSpec{{1, "d"}}},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.specs, ParseFormatSpecs(tt.str), tt.str)
+	}
+}
+
+func TestFindFormatTokens(t *testing.T) {
+	assert.Equal(t, []FormatToken{
+		{Start: 0, End: 4, Index: "1$", Conversion: "@"},
+		{Start: 9, End: 12, Length: "l", Conversion: "u"},
+		{Start: 21, End: 28, Flags: "-", Width: "10.2", Conversion: "f"},
+		{Start: 33, End: 35, Conversion: "%"},
+	}, FindFormatTokens("%1$@ has %lu, % sure %-10.2f, 100%% % d"))
+
+	assert.Equal(t, "[s] and [d], 100% sure", ReplaceFormatTokens("%s and %2$d, 100% sure",
+		func(t FormatToken) string { return "[" + t.Conversion + "]" }))
+}
+
+func TestCompareFormatSpecs(t *testing.T) {
+	tbl := []struct {
+		source       string
+		translation  string
+		allowMissing bool
+		problems     []string
+	}{
+		{"%1$s has %2$d apples", "%2$d Äpfel hat %1$s", false, nil},
+		{"%s has %d apples", "%1$s hat %2$d Äpfel", false, nil},
+		{"Hello, %1$s!", "Hallo!", false, []string{"missing %1$s"}},
+		{"Hello, %1$s!", "Hallo, %1$s %2$s!", false, []string{"extra %2$s"}},
+		{"%d apples", "%s Äpfel", false, []string{"%1$s instead of %1$d"}},
+		{"%d apples", "Ein Apfel", true, nil},
+		{"%d apples", "%d Apfel %s", true, []string{"extra %2$s"}},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.problems, CompareFormatSpecs(
+			ParseFormatSpecs(tt.source), ParseFormatSpecs(tt.translation), tt.allowMissing,
+		), tt.translation)
+	}
+}
+
+func TestValidateFormats(t *testing.T) {
+	dicts := Dictionaries{
+		"en": DictionaryOf(
+			"greeting", "Hello, %1$s!",
+			"apples#one", "One apple",
+			"apples#other", "%d apples",
+		),
+		"de": DictionaryOf(
+			"greeting", "Hallo!",
+			"apples#one", "Ein Apfel",
+			"apples#other", "%d Äpfel",
+		),
+		"ru": DictionaryOf(
+			"greeting", "Привет, %1$s!",
+			"apples#few", "%s яблока",
+		),
+		TranslatableMeta: DictionaryOf(
+			"greeting", "false",
+		),
+	}
+	assert.Equal(t, []string{
+		"de: greeting: missing %1$s",
+		"ru: apples#few: %1$s instead of %1$d",
+	}, ValidateFormats(dicts, "en"))
+}
@@ -2,10 +2,285 @@
 // structs for working with dictionaries
 package general
 
-// Dictionary defines a single dictionary in
-// format map[code]translation
-type Dictionary = map[string]string
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultBaseLanguage defines the default language code for
+// strings from the default "values" folder
+const DefaultBaseLanguage = "default"
+
+// MetaPrefix defines the prefix of codes of dictionaries, that keep
+// attributes of strings instead of translations
+const MetaPrefix = "@"
+
+// TranslatableMeta defines the code of dictionary with values of
+// "translatable" attribute of strings, e.g. map[code]"false"
+const TranslatableMeta = MetaPrefix + "translatable"
+
+// FileMeta defines the code of dictionary with names of xml files in values
+// folders, that strings are read from, e.g. map[code]"errors.xml", strings
+// from the default strings.xml file are not listed
+const FileMeta = MetaPrefix + "file"
+
+// ModuleMeta defines the code of dictionary with paths of "res" folders relative
+// to the root of android project, that strings are read from, e.g.
+// map[code]"app/src/main/res", the path defines both module and source set
+const ModuleMeta = MetaPrefix + "module"
+
+// DescriptionMeta defines the code of dictionary with descriptions of strings for translators,
+// e.g. map[code]"Title of the main screen", descriptions are taken from xml comments right
+// before strings in the default "values" folder
+const DescriptionMeta = MetaPrefix + "description"
+
+// PlaceholdersMeta defines the code of dictionary with <xliff:g> spans of strings of the base
+// language, that are replaced with tokens like "{count}" in translations, e.g.
+// map[code]`<xliff:g id="count">%d</xliff:g>`
+const PlaceholdersMeta = MetaPrefix + "placeholders"
+
+// StateMetaPrefix defines the prefix of codes of dictionaries with review states of translations
+// to the language, e.g. "@state:de" keeps states of german translations, e.g. map[code]"needs_review",
+// translations, that are not listed, are treated as translated
+const StateMetaPrefix = MetaPrefix + "state:"
+
+const (
+	// StateTranslated defines the state of translation, that is done
+	StateTranslated = "translated"
+	// StateNeedsReview defines the state of translation, that has to be reviewed
+	StateNeedsReview = "needs_review"
+)
+
+// StateMeta returns the code of dictionary with review states of translations to the given language
+func StateMeta(langCode string) string {
+	return StateMetaPrefix + langCode
+}
+
+// ParseStateMeta returns the language of dictionary with review states of translations,
+// ok is false if the code doesn't define such dictionary
+func ParseStateMeta(code string) (langCode string, ok bool) {
+	if !strings.HasPrefix(code, StateMetaPrefix) {
+		return "", false
+	}
+	return code[len(StateMetaPrefix):], true
+}
+
+// IsMeta reports whether the dictionary with the given code keeps
+// attributes of strings instead of translations
+func IsMeta(langCode string) bool {
+	return strings.HasPrefix(langCode, MetaPrefix)
+}
+
+// Dictionary defines a single dictionary in format map[code]translation,
+// that keeps codes in the order they were added
+type Dictionary struct {
+	codes  []string          // codes in the order they were added
+	values map[string]string // translations by their codes
+}
 
 // Dictionaries defines a set of dictionaries
-// in format map[languageCode]Dictionary
-type Dictionaries = map[string]Dictionary
+// in format map[languageCode]*Dictionary
+type Dictionaries = map[string]*Dictionary
+
+// NewDictionary makes an empty dictionary
+func NewDictionary() *Dictionary {
+	return &Dictionary{codes: []string{}, values: map[string]string{}}
+}
+
+// DictionaryOf makes a dictionary of the given codes and translations,
+// that go in pairs like "code1", "translation1", "code2", "translation2" ...
+func DictionaryOf(pairs ...string) *Dictionary {
+	d := NewDictionary()
+	for i := 0; i+1 < len(pairs); i += 2 {
+		d.Set(pairs[i], pairs[i+1])
+	}
+	return d
+}
+
+// Set sets the translation for the given code, new codes
+// are added to the end of dictionary
+func (d *Dictionary) Set(code string, translation string) {
+	if _, exists := d.values[code]; !exists {
+		d.codes = append(d.codes, code)
+	}
+	d.values[code] = translation
+}
+
+// Get returns the translation for the given code or an empty
+// string if there is no such code in the dictionary
+func (d *Dictionary) Get(code string) string {
+	translation, _ := d.Lookup(code)
+	return translation
+}
+
+// Lookup returns the translation for the given code, ok
+// is false if there is no such code in the dictionary
+func (d *Dictionary) Lookup(code string) (translation string, ok bool) {
+	if d == nil {
+		return "", false
+	}
+	translation, ok = d.values[code]
+	return
+}
+
+// Delete removes the given code from the dictionary
+func (d *Dictionary) Delete(code string) {
+	if _, exists := d.values[code]; !exists {
+		return
+	}
+	delete(d.values, code)
+	for i, c := range d.codes {
+		if c == code {
+			d.codes = append(d.codes[:i], d.codes[i+1:]...)
+			break
+		}
+	}
+}
+
+// Codes returns codes of the dictionary in the order they were added
+func (d *Dictionary) Codes() []string {
+	if d == nil {
+		return nil
+	}
+	return append([]string{}, d.codes...)
+}
+
+// Len returns the number of codes in the dictionary
+func (d *Dictionary) Len() int {
+	if d == nil {
+		return 0
+	}
+	return len(d.codes)
+}
+
+// Sort sorts codes of the dictionary in alphabetical order
+func (d *Dictionary) Sort() {
+	sort.Strings(d.codes)
+}
+
+// OrderedLike returns the copy of the dictionary with codes in the order of
+// the given dictionary, codes missing in it go after in their own order
+func (d *Dictionary) OrderedLike(base *Dictionary) *Dictionary {
+	res := NewDictionary()
+	for _, code := range base.Codes() {
+		if translation, ok := d.Lookup(code); ok {
+			res.Set(code, translation)
+		}
+	}
+	for _, code := range d.Codes() {
+		if _, ok := res.Lookup(code); !ok {
+			res.Set(code, d.Get(code))
+		}
+	}
+	return res
+}
+
+// LanguageDictionary returns the dictionary of the given language from the given set,
+// an empty dictionary is added to the set if there is no such language
+func LanguageDictionary(dicts Dictionaries, langCode string) *Dictionary {
+	if dicts[langCode] == nil {
+		dicts[langCode] = NewDictionary()
+	}
+	return dicts[langCode]
+}
+
+// SortedLanguages returns codes of the given dictionaries in the stable order:
+// the base language goes first, then other languages in alphabetical order of BCP-47 tags,
+// then dictionaries with attributes of strings in alphabetical order
+func SortedLanguages(dicts Dictionaries, baseLang string) (langCodes []string) {
+	for langCode := range dicts {
+		langCodes = append(langCodes, langCode)
+	}
+
+	rank := func(langCode string) int {
+		switch {
+		case langCode == baseLang:
+			return 0
+		case IsMeta(langCode):
+			return 2
+		default:
+			return 1
+		}
+	}
+
+	// locales are compared in BCP-47 format, so "b+zh+Hant" goes after "pt-rBR"
+	key := func(langCode string) string {
+		if l, ok := ParseLocale(langCode); ok {
+			return l.BCP47()
+		}
+		return langCode
+	}
+
+	sort.Slice(langCodes, func(i, j int) bool {
+		if ri, rj := rank(langCodes[i]), rank(langCodes[j]); ri != rj {
+			return ri < rj
+		}
+		return key(langCodes[i]) < key(langCodes[j])
+	})
+	return
+}
+
+// PluralSeparator separates the name of plurals resource and the
+// quantity of its item in dictionary codes, e.g. "apples#one"
+const PluralSeparator = "#"
+
+// PluralQuantities defines quantities of items of plurals in the order they are written
+var PluralQuantities = []string{"zero", "one", "two", "few", "many", "other"}
+
+// PluralCode returns the dictionary code for the item of plurals
+// resource with the given name and quantity
+func PluralCode(name string, quantity string) string {
+	return name + PluralSeparator + quantity
+}
+
+// ParsePluralCode splits the given dictionary code to the name of plurals
+// resource and the quantity of its item, ok is false if the code
+// doesn't define an item of plurals resource
+func ParsePluralCode(code string) (name string, quantity string, ok bool) {
+	i := strings.LastIndex(code, PluralSeparator)
+	if i < 0 {
+		return "", "", false
+	}
+	return code[:i], code[i+len(PluralSeparator):], true
+}
+
+// ArrayCode returns the dictionary code for the item of string-array
+// resource with the given name and index, e.g. "planets[0]"
+func ArrayCode(name string, index int) string {
+	return name + "[" + strconv.Itoa(index) + "]"
+}
+
+// ParseArrayCode splits the given dictionary code to the name of string-array
+// resource and the index of its item, ok is false if the code
+// doesn't define an item of string-array resource
+func ParseArrayCode(code string) (name string, index int, ok bool) {
+	i := strings.LastIndex(code, "[")
+	if i < 0 || !strings.HasSuffix(code, "]") {
+		return "", 0, false
+	}
+	index, err := strconv.Atoi(code[i+1 : len(code)-1])
+	if err != nil || index < 0 {
+		return "", 0, false
+	}
+	return code[:i], index, true
+}
+
+// ModuleSeparator separates the path of "res" folder and the code of string
+// in codes of strings of multi-module projects, e.g. "app/src/main/res:title"
+const ModuleSeparator = ":"
+
+// ModuleCode returns the code of string from the "res" folder at the given path
+func ModuleCode(module string, code string) string {
+	return module + ModuleSeparator + code
+}
+
+// ParseModuleCode splits the given code to the path of "res" folder and the
+// code of string in it, ok is false if the code doesn't contain the path
+func ParseModuleCode(moduleCode string) (module string, code string, ok bool) {
+	i := strings.LastIndex(moduleCode, ModuleSeparator)
+	if i < 0 {
+		return "", moduleCode, false
+	}
+	return moduleCode[:i], moduleCode[i+len(ModuleSeparator):], true
+}
@@ -0,0 +1,45 @@
+package general
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortedLanguages(t *testing.T) {
+	langCodes := SortedLanguages(Dictionaries{
+		"ru":             NewDictionary(),
+		TranslatableMeta: NewDictionary(),
+		"de":             NewDictionary(),
+		"en":             NewDictionary(),
+	}, "en")
+	assert.Equal(t, []string{"en", "de", "ru", TranslatableMeta}, langCodes)
+}
+
+func TestDictionaryOrder(t *testing.T) {
+	d := DictionaryOf("c_str", "c", "a_str", "a", "b_str", "b")
+	assert.Equal(t, []string{"c_str", "a_str", "b_str"}, d.Codes())
+
+	d.Set("a_str", "a2")
+	d.Set("d_str", "d")
+	d.Delete("c_str")
+	assert.Equal(t, []string{"a_str", "b_str", "d_str"}, d.Codes())
+	assert.Equal(t, "a2", d.Get("a_str"))
+	assert.Equal(t, "", d.Get("c_str"))
+
+	d.Sort()
+	assert.Equal(t, []string{"a_str", "b_str", "d_str"}, d.Codes())
+
+	ordered := d.OrderedLike(DictionaryOf("d_str", "", "x_str", "", "b_str", ""))
+	assert.Equal(t, DictionaryOf("d_str", "d", "b_str", "b", "a_str", "a2"), ordered)
+}
+
+func TestLanguageDictionary(t *testing.T) {
+	dicts := Dictionaries{"en": DictionaryOf("title", "Title")}
+	LanguageDictionary(dicts, "en").Set("subtitle", "Subtitle")
+	LanguageDictionary(dicts, "de").Set("title", "Titel")
+	assert.Equal(t, Dictionaries{
+		"en": DictionaryOf("title", "Title", "subtitle", "Subtitle"),
+		"de": DictionaryOf("title", "Titel"),
+	}, dicts)
+}
@@ -0,0 +1,181 @@
+package general
+
+import "strings"
+
+// Locale defines the locale of translations, that can be written as android
+// resource qualifier, e.g. "pt-rBR" or "b+zh+Hant+TW", or as BCP-47 tag, e.g. "zh-Hant-TW"
+type Locale struct {
+	Language string // ISO 639 language code in lower case, e.g. "pt"
+	Script   string // ISO 15924 script code in title case, e.g. "Latn"
+	Region   string // ISO 3166-1 region code in upper case or UN M.49 region number, e.g. "BR" or "419"
+}
+
+// BCP47Prefix defines the prefix of locale qualifier in BCP-47 format, e.g. "b+sr+Latn"
+const BCP47Prefix = "b+"
+
+// nonLocaleQualifiers defines qualifiers, that look like language codes, but aren't
+var nonLocaleQualifiers = map[string]bool{
+	"car": true, // ui mode
+	"hdr": true, // high dynamic range
+}
+
+// isLetters reports whether the given string consists only of ascii letters in the given case
+func isLetters(s string, upper bool) bool {
+	for _, c := range s {
+		if upper && (c < 'A' || c > 'Z') || !upper && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isDigits reports whether the given string consists only of ascii digits
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// isLanguage reports whether the given string is a language code of android resource qualifier
+func isLanguage(s string) bool {
+	return (len(s) == 2 || len(s) == 3) && isLetters(strings.ToLower(s), false) && !nonLocaleQualifiers[strings.ToLower(s)]
+}
+
+// isRegion reports whether the given string is a region code or number of BCP-47 tag
+func isRegion(s string) bool {
+	return len(s) == 2 && isLetters(strings.ToUpper(s), true) || len(s) == 3 && isDigits(s)
+}
+
+// isScript reports whether the given string is a script code of BCP-47 tag
+func isScript(s string) bool {
+	return len(s) == 4 && isLetters(strings.ToLower(s), false)
+}
+
+// normalized returns the locale with codes in their canonical case
+func (l Locale) normalized() Locale {
+	l.Language = strings.ToLower(l.Language)
+	l.Region = strings.ToUpper(l.Region)
+	if l.Script != "" {
+		l.Script = strings.ToUpper(l.Script[:1]) + strings.ToLower(l.Script[1:])
+	}
+	return l
+}
+
+// Qualifier returns the locale in format of android resource qualifier, e.g. "pt-rBR",
+// locales with script or region number are returned in BCP-47 format, e.g. "b+sr+Latn"
+func (l Locale) Qualifier() string {
+	if l.Script == "" && !isDigits(l.Region) {
+		if l.Region == "" {
+			return l.Language
+		}
+		return l.Language + "-r" + l.Region
+	}
+
+	parts := []string{l.Language}
+	if l.Script != "" {
+		parts = append(parts, l.Script)
+	}
+	if l.Region != "" {
+		parts = append(parts, l.Region)
+	}
+	return BCP47Prefix + strings.Join(parts, "+")
+}
+
+// parseLocaleQualifiers parses the locale from the first of the given dash-separated resource
+// qualifiers, n is the number of qualifiers, that define the locale, or zero if there is no locale
+func parseLocaleQualifiers(qualifiers []string) (l Locale, n int) {
+	if len(qualifiers) == 0 {
+		return l, 0
+	}
+
+	// BCP-47 format, e.g. "b+sr+Latn+RS"
+	if strings.HasPrefix(qualifiers[0], BCP47Prefix) {
+		tag := strings.Replace(qualifiers[0][len(BCP47Prefix):], "+", "-", -1)
+		if l, ok := ParseBCP47(tag); ok {
+			return l, 1
+		}
+		return Locale{}, 0
+	}
+
+	// legacy format, e.g. "pt-rBR"
+	if !isLanguage(qualifiers[0]) {
+		return l, 0
+	}
+	l.Language = qualifiers[0]
+	n = 1
+	if len(qualifiers) > 1 && len(qualifiers[1]) == 3 && qualifiers[1][0] == 'r' && isRegion(qualifiers[1][1:]) {
+		l.Region = qualifiers[1][1:]
+		n = 2
+	}
+	return l.normalized(), n
+}
+
+// ParseLocaleQualifier parses the given android locale qualifier, e.g. "pt-rBR" or "b+sr+Latn",
+// ok is false if the qualifier doesn't define a locale or contains other qualifiers
+func ParseLocaleQualifier(qualifier string) (l Locale, ok bool) {
+	qualifiers := strings.Split(qualifier, "-")
+	l, n := parseLocaleQualifiers(qualifiers)
+	return l, n > 0 && n == len(qualifiers)
+}
+
+// BCP47 returns the locale as BCP-47 tag, e.g. "pt-BR" or "zh-Hant-TW"
+func (l Locale) BCP47() string {
+	parts := []string{l.Language}
+	if l.Script != "" {
+		parts = append(parts, l.Script)
+	}
+	if l.Region != "" {
+		parts = append(parts, l.Region)
+	}
+	return strings.Join(parts, "-")
+}
+
+// ParseBCP47 parses the given BCP-47 tag, e.g. "pt-BR" or "zh-Hant-TW", tags with
+// variants and extensions are not supported
+func ParseBCP47(tag string) (l Locale, ok bool) {
+	subtags := strings.Split(tag, "-")
+	if !isLanguage(subtags[0]) {
+		return l, false
+	}
+	l.Language = subtags[0]
+	for _, subtag := range subtags[1:] {
+		switch {
+		case l.Script == "" && l.Region == "" && isScript(subtag):
+			l.Script = subtag
+		case l.Region == "" && isRegion(subtag):
+			l.Region = subtag
+		default:
+			return Locale{}, false
+		}
+	}
+	return l.normalized(), true
+}
+
+// ParseLocale parses the given language code either as android locale qualifier
+// or as BCP-47 tag
+func ParseLocale(langCode string) (l Locale, ok bool) {
+	if l, ok = ParseLocaleQualifier(langCode); ok {
+		return l, true
+	}
+	return ParseBCP47(langCode)
+}
+
+// DefaultSourceLanguage defines the BCP-47 tag of the base language in formats, that
+// require a locale, e.g. string catalogs or ARB files, if the base language is not a
+// locale, e.g. "default"
+const DefaultSourceLanguage = "en"
+
+// SourceLanguage returns the BCP-47 tag of the base language: the given tag if it
+// is set, the base language code if it is a locale or DefaultSourceLanguage
+func SourceLanguage(tag string, baseLang string) string {
+	if tag != "" {
+		return tag
+	}
+	if l, ok := ParseLocale(baseLang); ok {
+		return l.BCP47()
+	}
+	return DefaultSourceLanguage
+}
@@ -0,0 +1,35 @@
+package general
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocaleFormats(t *testing.T) {
+	tbl := []struct {
+		langCode  string
+		locale    Locale
+		qualifier string
+		bcp47     string
+	}{
+		{"de", Locale{Language: "de"}, "de", "de"},
+		{"pt-rBR", Locale{Language: "pt", Region: "BR"}, "pt-rBR", "pt-BR"},
+		{"pt-BR", Locale{Language: "pt", Region: "BR"}, "pt-rBR", "pt-BR"},
+		{"b+zh+Hant+TW", Locale{Language: "zh", Script: "Hant", Region: "TW"}, "b+zh+Hant+TW", "zh-Hant-TW"},
+		{"zh-hant-tw", Locale{Language: "zh", Script: "Hant", Region: "TW"}, "b+zh+Hant+TW", "zh-Hant-TW"},
+		{"es-419", Locale{Language: "es", Region: "419"}, "b+es+419", "es-419"},
+	}
+	for _, tt := range tbl {
+		locale, ok := ParseLocale(tt.langCode)
+		assert.True(t, ok, tt.langCode)
+		assert.Equal(t, tt.locale, locale, tt.langCode)
+		assert.Equal(t, tt.qualifier, locale.Qualifier(), tt.langCode)
+		assert.Equal(t, tt.bcp47, locale.BCP47(), tt.langCode)
+	}
+
+	for _, langCode := range []string{"default", "night", "pt-rBR-land", "en-US-x-private", ""} {
+		_, ok := ParseLocale(langCode)
+		assert.False(t, ok, langCode)
+	}
+}
@@ -0,0 +1,32 @@
+package general
+
+import "regexp"
+
+// XLIFFTagRe matches <xliff:g> tags, that mark untranslatable parts of android strings
+var XLIFFTagRe = regexp.MustCompile(`</?xliff:g\b[^>]*>`)
+
+// StripXLIFF returns the given android string without <xliff:g> tags, contents of spans are kept
+func StripXLIFF(value string) string {
+	return XLIFFTagRe.ReplaceAllString(value, "")
+}
+
+// KeepMarkup replaces values of the given set of dictionaries, that are equal to exported values of
+// strings of the existing set, with the existing values, exported values are the ones, that android
+// strings become after export to another format and import back, e.g. without <xliff:g> tags, so
+// strings read back from other formats don't overwrite the markup of android strings
+func KeepMarkup(dicts Dictionaries, existing Dictionaries, exported Dictionaries) {
+	for langCode, d := range dicts {
+		if IsMeta(langCode) {
+			continue
+		}
+		for _, code := range d.Codes() {
+			old, ok := existing[langCode].Lookup(code)
+			if !ok || old == d.Get(code) {
+				continue
+			}
+			if value, ok := exported[langCode].Lookup(code); ok && value == d.Get(code) {
+				d.Set(code, old)
+			}
+		}
+	}
+}
@@ -0,0 +1,38 @@
+package general
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripXLIFF(t *testing.T) {
+	assert.Equal(t, "Hello, %1$s! <b>Bold</b>",
+		StripXLIFF(`Hello, <xliff:g id="name" example="Bob">%1$s</xliff:g>! <b>Bold</b>`))
+}
+
+func TestKeepMarkup(t *testing.T) {
+	dicts := Dictionaries{
+		"en": DictionaryOf(
+			"items", "%d items",
+			"title", "New title",
+		),
+	}
+	KeepMarkup(dicts, Dictionaries{
+		"en": DictionaryOf(
+			"items", `<xliff:g id="count">%d</xliff:g> items`,
+			"title", "<b>Title</b>",
+		),
+	}, Dictionaries{
+		"en": DictionaryOf(
+			"items", "%d items",
+			"title", "Title",
+		),
+	})
+	assert.Equal(t, Dictionaries{
+		"en": DictionaryOf(
+			"items", `<xliff:g id="count">%d</xliff:g> items`,
+			"title", "New title",
+		),
+	}, dicts)
+}
@@ -0,0 +1,210 @@
+// Package ios specifies functions and structs for writing dictionaries to
+// .strings and .stringsdict files of iOS projects and reading them back
+package ios
+
+import (
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// StringsFilename defines the name of file with strings
+	StringsFilename = "Localizable.strings"
+	// StringsdictFilename defines the name of file with plurals
+	StringsdictFilename = "Localizable.stringsdict"
+	// LprojExtension defines the extension of folders with localized resources
+	LprojExtension = ".lproj"
+	// BaseLproj defines the name of folder with resources of the base language
+	BaseLproj = "Base" + LprojExtension
+)
+
+// Options defines the options of writing and reading iOS localization folders
+type Options struct {
+	// BaseLanguage defines the language code of strings in the "Base.lproj" folder
+	BaseLanguage string
+	// SourceLanguage defines the BCP-47 tag of the base language in new string catalogs,
+	// by default it is made of the base language or general.DefaultSourceLanguage is used
+	SourceLanguage string
+}
+
+// ToIOSFormat converts format specifiers of android string to iOS ones: strings are
+// formatted with "@" conversion, e.g. "%1$s" is converted to "%1$@"
+func ToIOSFormat(value string) string {
+	return general.ReplaceFormatTokens(value, func(t general.FormatToken) string {
+		if !t.Android() || (t.Conversion != "s" && t.Conversion != "S") {
+			return value[t.Start:t.End]
+		}
+		return "%" + t.Index + t.Flags + t.Width + "@"
+	})
+}
+
+// ToAndroidFormat converts format specifiers of iOS string to android ones: objects are
+// formatted with "s" conversion, e.g. "%1$@" is converted to "%1$s", length modifiers are
+// removed and unsigned and "i" integers are formatted with "d" conversion, e.g. "%lu" is
+// converted to "%d"
+func ToAndroidFormat(value string) string {
+	return general.ReplaceFormatTokens(value, func(t general.FormatToken) string {
+		conversion := t.Conversion
+		switch conversion {
+		case "%":
+			return value[t.Start:t.End]
+		case "@":
+			conversion = "s"
+		case "u", "i", "D", "U":
+			conversion = "d"
+		}
+		return "%" + t.Index + t.Flags + t.Width + conversion
+	})
+}
+
+// KeepMarkup replaces values of the given set of dictionaries, that differ from values of the
+// existing set only in <xliff:g> tags and format specifiers, that are changed on export to iOS,
+// with the existing values (see general.KeepMarkup)
+func KeepMarkup(dicts general.Dictionaries, existing general.Dictionaries) {
+	exported := make(general.Dictionaries)
+	for langCode, d := range existing {
+		for _, code := range d.Codes() {
+			general.LanguageDictionary(exported, langCode).Set(code,
+				ToAndroidFormat(ToIOSFormat(general.StripXLIFF(d.Get(code)))))
+		}
+	}
+	general.KeepMarkup(dicts, existing, exported)
+}
+
+// lprojName returns the name of folder with resources of the given language, e.g. "pt-BR.lproj"
+func lprojName(langCode string, baseLang string) string {
+	if langCode == baseLang {
+		return BaseLproj
+	}
+	return lprojTag(langCode) + LprojExtension
+}
+
+// lprojTag returns the BCP-47 tag of the given language code
+func lprojTag(langCode string) string {
+	if l, ok := general.ParseLocale(langCode); ok {
+		return l.BCP47()
+	}
+	return langCode
+}
+
+// WriteIOSFolder writes the given set of dictionaries to "xx.lproj" folders in the folder at the
+// given path, strings of the base language go to the "Base.lproj" folder, plurals are written to
+// Localizable.stringsdict files, other strings are written to Localizable.strings files in the
+// order of the base language, format specifiers are converted to iOS ones (see ToIOSFormat),
+// <xliff:g> tags are removed as iOS doesn't support them
+func WriteIOSFolder(path string, dicts general.Dictionaries, opts Options) (files []*os.File, err error) {
+	files = []*os.File{}
+	attrs := dicts[general.TranslatableMeta]
+	base := dicts[opts.BaseLanguage]
+
+	for _, langCode := range general.SortedLanguages(dicts, opts.BaseLanguage) {
+		if general.IsMeta(langCode) {
+			continue
+		}
+
+		strs, plurals := general.NewDictionary(), general.NewDictionary()
+		d := dicts[langCode].OrderedLike(base)
+		for _, code := range d.Codes() {
+			if langCode != opts.BaseLanguage && attrs.Get(code) == "false" {
+				continue
+			}
+			value := ToIOSFormat(general.StripXLIFF(d.Get(code)))
+			if _, _, ok := general.ParsePluralCode(code); ok {
+				plurals.Set(code, value)
+				continue
+			}
+			strs.Set(code, value)
+		}
+
+		lprojPath := filepath.Join(path, lprojName(langCode, opts.BaseLanguage))
+		if err = os.MkdirAll(lprojPath, os.ModePerm); err != nil {
+			return
+		}
+
+		var file *os.File
+		if strs.Len() > 0 {
+			file, err = WriteStringsFile(filepath.Join(lprojPath, StringsFilename), strs)
+			if file != nil {
+				files = append(files, file)
+			}
+			if err != nil {
+				return
+			}
+		}
+		if plurals.Len() > 0 {
+			file, err = WriteStringsdictFile(filepath.Join(lprojPath, StringsdictFilename), plurals)
+			if file != nil {
+				files = append(files, file)
+			}
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// ReadIOSFolder reads Localizable.strings and Localizable.stringsdict files of all "xx.lproj"
+// folders in the folder at the given path, strings from the "Base.lproj" folder are stored under
+// the base language code, strings from other folders are stored under android locale qualifiers,
+// e.g. "pt-rBR", format specifiers are converted to android ones (see ToAndroidFormat)
+func ReadIOSFolder(path string, opts Options) (dicts general.Dictionaries, err error) {
+	contents, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dicts = make(general.Dictionaries)
+
+	for _, entry := range contents {
+		if !entry.IsDir() || filepath.Ext(entry.Name()) != LprojExtension {
+			continue
+		}
+
+		langCode := opts.BaseLanguage
+		if entry.Name() != BaseLproj {
+			tag := strings.TrimSuffix(entry.Name(), LprojExtension)
+			l, ok := general.ParseBCP47(tag)
+			if !ok {
+				continue
+			}
+			langCode = l.Qualifier()
+		}
+
+		d := general.NewDictionary()
+		for _, read := range []struct {
+			filename string
+			fn       func(string) (*general.Dictionary, error)
+		}{
+			{StringsFilename, ReadStringsFile},
+			{StringsdictFilename, ReadStringsdictFile},
+		} {
+			filePath := filepath.Join(path, entry.Name(), read.filename)
+			if _, err = os.Stat(filePath); os.IsNotExist(err) {
+				continue
+			}
+			var fd *general.Dictionary
+			if fd, err = read.fn(filePath); err != nil {
+				return nil, err
+			}
+			for _, code := range fd.Codes() {
+				d.Set(code, ToAndroidFormat(fd.Get(code)))
+			}
+		}
+
+		if d.Len() == 0 {
+			continue
+		}
+		if dicts[langCode] != nil {
+			return nil, fmt.Errorf("%s: duplicated language %s", entry.Name(), langCode)
+		}
+		dicts[langCode] = d
+	}
+
+	return dicts, nil
+}
@@ -0,0 +1,103 @@
+package ios
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatConversions(t *testing.T) {
+	tbl := []struct {
+		android string
+		ios     string
+	}{
+		{"Hello, %1$s!", "Hello, %1$@!"},
+		{"%s has %d apples", "%@ has %d apples"},
+		{"%2$s of %1$.2f%%", "%2$@ of %1$.2f%%"},
+		{"%-10s|", "%-10@|"},
+		{"Plain text", "Plain text"},
+		{"100% sure, 20% Discount", "100% sure, 20% Discount"},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.ios, ToIOSFormat(tt.android), tt.android)
+		assert.Equal(t, tt.android, ToAndroidFormat(tt.ios), tt.ios)
+	}
+	assert.Equal(t, "%d files of %d", ToAndroidFormat("%lu files of %ld"))
+}
+
+func TestKeepMarkup(t *testing.T) {
+	dicts := general.Dictionaries{
+		"en": general.DictionaryOf(
+			"items", "%d items",
+			"title", "New title",
+		),
+		"de": general.DictionaryOf(
+			"items", "%d Dinge",
+		),
+	}
+	KeepMarkup(dicts, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"items", `<xliff:g id="count">%d</xliff:g> items`,
+			"title", "<b>Title</b>",
+		),
+		"de": general.DictionaryOf(
+			"items", `<xliff:g id="count">%d</xliff:g> Elemente`,
+		),
+	})
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"items", `<xliff:g id="count">%d</xliff:g> items`,
+			"title", "New title",
+		),
+		"de": general.DictionaryOf(
+			"items", "%d Dinge",
+		),
+	}, dicts)
+}
+
+func TestReadWriteIOSFolder(t *testing.T) {
+	defer os.RemoveAll("/tmp/ios")
+	dicts := general.Dictionaries{
+		"en": general.DictionaryOf(
+			"greeting", `Hello, <xliff:g id="name">%1$s</xliff:g>!`,
+			"apples#one", "%d apple",
+			"apples#other", "%d apples",
+			"app_name", "App",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"apples#other", "%d maçãs",
+			"greeting", "Olá, %1$s!",
+		),
+		general.TranslatableMeta: general.DictionaryOf(
+			"app_name", "false",
+		),
+	}
+	_, err := WriteIOSFolder("/tmp/ios", dicts, Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.FileExists(t, "/tmp/ios/Base.lproj/Localizable.strings")
+	assert.FileExists(t, "/tmp/ios/Base.lproj/Localizable.stringsdict")
+	assert.FileExists(t, "/tmp/ios/pt-BR.lproj/Localizable.strings")
+
+	data, err := ioutil.ReadFile("/tmp/ios/pt-BR.lproj/Localizable.strings")
+	require.NoError(t, err)
+	assert.Equal(t, "\"greeting\" = \"Olá, %1$@!\";\n", string(data))
+
+	read, err := ReadIOSFolder("/tmp/ios", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"greeting", "Hello, %1$s!",
+			"app_name", "App",
+			"apples#one", "%d apple",
+			"apples#other", "%d apples",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"greeting", "Olá, %1$s!",
+			"apples#other", "%d maçãs",
+		),
+	}, read)
+}
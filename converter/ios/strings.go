@@ -0,0 +1,211 @@
+package ios
+
+import (
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// quoteString quotes the given string for .strings file, quotes, backslashes,
+// new lines, carriage returns and tabs are escaped
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// formatStrings returns the content of .strings file with the given dictionary,
+// lines go in the order of the dictionary, e.g. "title" = "Title";
+func formatStrings(d *general.Dictionary) string {
+	var b strings.Builder
+	for _, code := range d.Codes() {
+		b.WriteString(quoteString(code) + " = " + quoteString(d.Get(code)) + ";\n")
+	}
+	return b.String()
+}
+
+// stringsParser parses the content of .strings file
+type stringsParser struct {
+	data string
+	pos  int
+	line int
+}
+
+// errorf returns the error with the current line of the file
+func (p *stringsParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: %s", p.line, fmt.Sprintf(format, args...))
+}
+
+// skipSpaces skips whitespaces and comments
+func (p *stringsParser) skipSpaces() error {
+	for p.pos < len(p.data) {
+		switch {
+		case p.data[p.pos] == '\n':
+			p.line++
+			p.pos++
+		case p.data[p.pos] == ' ' || p.data[p.pos] == '\t' || p.data[p.pos] == '\r':
+			p.pos++
+		case strings.HasPrefix(p.data[p.pos:], "//"):
+			end := strings.IndexByte(p.data[p.pos:], '\n')
+			if end < 0 {
+				p.pos = len(p.data)
+				break
+			}
+			p.pos += end
+		case strings.HasPrefix(p.data[p.pos:], "/*"):
+			end := strings.Index(p.data[p.pos+2:], "*/")
+			if end < 0 {
+				return p.errorf("unterminated comment")
+			}
+			p.line += strings.Count(p.data[p.pos:p.pos+2+end], "\n")
+			p.pos += end + 4
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+// expect skips whitespaces and the given character
+func (p *stringsParser) expect(c byte) error {
+	if err := p.skipSpaces(); err != nil {
+		return err
+	}
+	if p.pos >= len(p.data) || p.data[p.pos] != c {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+// isBareChar reports whether the given character may be a part of unquoted string
+func isBareChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' ||
+		c == '_' || c == '.' || c == '-' || c == '$' || c == '/' || c == ':'
+}
+
+// str parses quoted or unquoted string
+func (p *stringsParser) str() (string, error) {
+	if err := p.skipSpaces(); err != nil {
+		return "", err
+	}
+	if p.pos >= len(p.data) {
+		return "", p.errorf("unexpected end of file")
+	}
+
+	if p.data[p.pos] != '"' {
+		start := p.pos
+		for p.pos < len(p.data) && isBareChar(p.data[p.pos]) {
+			p.pos++
+		}
+		if start == p.pos {
+			return "", p.errorf("unexpected %q", p.data[p.pos])
+		}
+		return p.data[start:p.pos], nil
+	}
+
+	var b strings.Builder
+	for p.pos++; p.pos < len(p.data); p.pos++ {
+		c := p.data[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\\' && p.pos+1 < len(p.data):
+			p.pos++
+			switch p.data[p.pos] {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'U', 'u':
+				if p.pos+5 > len(p.data) {
+					return "", p.errorf("invalid unicode escape")
+				}
+				code, err := strconv.ParseUint(p.data[p.pos+1:p.pos+5], 16, 32)
+				if err != nil {
+					return "", p.errorf("invalid unicode escape")
+				}
+				b.WriteRune(rune(code))
+				p.pos += 4
+			default:
+				b.WriteByte(p.data[p.pos])
+			}
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			b.WriteByte(c)
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// parseStrings parses the content of .strings file to the dictionary
+func parseStrings(data string) (d *general.Dictionary, err error) {
+	d = general.NewDictionary()
+	p := &stringsParser{data: strings.TrimPrefix(data, "\uFEFF"), line: 1}
+	for {
+		if err = p.skipSpaces(); err != nil {
+			return nil, err
+		}
+		if p.pos >= len(p.data) {
+			return d, nil
+		}
+
+		var key, value string
+		if key, err = p.str(); err != nil {
+			return nil, err
+		}
+		if err = p.expect('='); err != nil {
+			return nil, err
+		}
+		if value, err = p.str(); err != nil {
+			return nil, err
+		}
+		if err = p.expect(';'); err != nil {
+			return nil, err
+		}
+		d.Set(key, value)
+	}
+}
+
+// WriteStringsFile writes the given dictionary to .strings file at the given path
+func WriteStringsFile(path string, d *general.Dictionary) (file *os.File, err error) {
+	return general.CreateFile(path, []byte(formatStrings(d)))
+}
+
+// ReadStringsFile reads .strings file at the given path, the file must be encoded in UTF-8
+func ReadStringsFile(path string) (d *general.Dictionary, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err = parseStrings(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,50 @@
+package ios
+
+import (
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatParseStrings(t *testing.T) {
+	d := general.DictionaryOf(
+		"title", "Title",
+		"quote", `Say "hi" \ bye`,
+		"lines", "First\nSecond\tTab",
+	)
+	data := formatStrings(d)
+	assert.Equal(t, `"title" = "Title";
+"quote" = "Say \"hi\" \\ bye";
+"lines" = "First\nSecond\tTab";
+`, data)
+
+	parsed, err := parseStrings(data)
+	require.NoError(t, err)
+	assert.Equal(t, d, parsed)
+}
+
+func TestParseStrings(t *testing.T) {
+	parsed, err := parseStrings("\uFEFF" + `/* Title of the main screen */
+"title" = "Title";
+// unquoted key
+subtitle = "Sub\U00E9";
+
+"multi" =
+	"Multi
+line";
+`)
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"title", "Title",
+		"subtitle", "Subé",
+		"multi", "Multi\nline",
+	), parsed)
+
+	_, err = parseStrings("\"title\" = \"Title\"\n\"next\" = \"Next\";")
+	assert.EqualError(t, err, `line 2: expected ';'`)
+
+	_, err = parseStrings(`"title" = "Title`)
+	assert.EqualError(t, err, `line 1: unterminated string`)
+}
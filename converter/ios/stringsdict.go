@@ -0,0 +1,247 @@
+package ios
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// plistHeader defines the header of property list files
+	plistHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+`
+	// formatKey defines the key of format of plural string in .stringsdict file
+	formatKey = "NSStringLocalizedFormatKey"
+	// pluralVariable defines the name of variable of plural strings written to .stringsdict file
+	pluralVariable = "value"
+)
+
+// variableRe matches variables in formats of plural strings, e.g. "%#@value@"
+var variableRe = regexp.MustCompile(`%(\d+\$)?#@([^@]+)@`)
+
+// plistDict defines <dict></dict> tag of property list with keys in the order of the file,
+// values are either strings or nested dictionaries
+type plistDict struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// str returns the string value with the given key or an empty string
+func (d *plistDict) str(key string) string {
+	s, _ := d.values[key].(string)
+	return s
+}
+
+// dict returns the nested dictionary with the given key or nil
+func (d *plistDict) dict(key string) *plistDict {
+	nested, _ := d.values[key].(*plistDict)
+	return nested
+}
+
+// decodeDict decodes the content of <dict></dict> tag, values other than
+// strings and dictionaries are skipped
+func decodeDict(decoder *xml.Decoder) (d *plistDict, err error) {
+	d = &plistDict{values: map[string]interface{}{}}
+	key := ""
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			var value interface{}
+			switch t.Name.Local {
+			case "key":
+				if err = decoder.DecodeElement(&key, &t); err != nil {
+					return nil, err
+				}
+				continue
+			case "string":
+				var s string
+				if err = decoder.DecodeElement(&s, &t); err != nil {
+					return nil, err
+				}
+				value = s
+			case "dict":
+				if value, err = decodeDict(decoder); err != nil {
+					return nil, err
+				}
+			default:
+				if err = decoder.Skip(); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			d.keys = append(d.keys, key)
+			d.values[key] = value
+		case xml.EndElement:
+			return d, nil
+		}
+	}
+}
+
+// parsePlist parses the root dictionary of property list
+func parsePlist(data []byte) (d *plistDict, err error) {
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("missing root dictionary")
+		}
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "dict" {
+			return decodeDict(decoder)
+		}
+	}
+}
+
+// plistWriter writes property list with tab indentation
+type plistWriter struct {
+	b     strings.Builder
+	depth int
+}
+
+// line writes the given xml on a separate line
+func (w *plistWriter) line(s string) {
+	w.b.WriteString(strings.Repeat("\t", w.depth) + s + "\n")
+}
+
+// escape escapes the given text for xml
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+// keyValue writes the key and its string value
+func (w *plistWriter) keyValue(key string, value string) {
+	w.line("<key>" + escape(key) + "</key>")
+	w.line("<string>" + escape(value) + "</string>")
+}
+
+// valueType returns the format of argument of plural string, e.g. "d" for "%d apples",
+// that is the conversion of the first format specifier
+func valueType(value string) string {
+	for _, t := range general.FindFormatTokens(value) {
+		if t.Conversion != "%" {
+			return t.Conversion
+		}
+	}
+	return "d"
+}
+
+// formatStringsdict returns the content of .stringsdict file with plurals of the given
+// dictionary, items of plurals are defined by codes like "name#quantity"
+func formatStringsdict(d *general.Dictionary) string {
+	names := []string{}
+	items := map[string]map[string]string{}
+	for _, code := range d.Codes() {
+		name, quantity, ok := general.ParsePluralCode(code)
+		if !ok {
+			continue
+		}
+		if items[name] == nil {
+			names = append(names, name)
+			items[name] = map[string]string{}
+		}
+		items[name][quantity] = d.Get(code)
+	}
+
+	w := &plistWriter{}
+	w.b.WriteString(plistHeader)
+	w.line(`<plist version="1.0">`)
+	w.line("<dict>")
+	w.depth++
+	for _, name := range names {
+		w.line("<key>" + escape(name) + "</key>")
+		w.line("<dict>")
+		w.depth++
+		w.keyValue(formatKey, "%#@"+pluralVariable+"@")
+		w.line("<key>" + pluralVariable + "</key>")
+		w.line("<dict>")
+		w.depth++
+		w.keyValue("NSStringFormatSpecTypeKey", "NSStringPluralRuleType")
+		sample := items[name]["other"]
+		for _, quantity := range general.PluralQuantities {
+			if value, ok := items[name][quantity]; ok && sample == "" {
+				sample = value
+			}
+		}
+		w.keyValue("NSStringFormatValueTypeKey", valueType(sample))
+		for _, quantity := range general.PluralQuantities {
+			if value, ok := items[name][quantity]; ok {
+				w.keyValue(quantity, value)
+			}
+		}
+		w.depth--
+		w.line("</dict>")
+		w.depth--
+		w.line("</dict>")
+	}
+	w.depth--
+	w.line("</dict>")
+	w.line("</plist>")
+	return w.b.String()
+}
+
+// parseStringsdict parses plurals from the content of .stringsdict file, the text around the
+// variable in the format of plural string is added to each item, e.g. "You have %#@apples@"
+// with item "%d apples" is read as "You have %d apples"
+func parseStringsdict(data []byte) (d *general.Dictionary, err error) {
+	root, err := parsePlist(data)
+	if err != nil {
+		return nil, err
+	}
+
+	d = general.NewDictionary()
+	for _, name := range root.keys {
+		entry := root.dict(name)
+		if entry == nil {
+			continue
+		}
+		format := entry.str(formatKey)
+		m := variableRe.FindStringSubmatchIndex(format)
+		if m == nil {
+			return nil, fmt.Errorf("%s: missing variable in %s", name, formatKey)
+		}
+		variable := entry.dict(format[m[4]:m[5]])
+		if variable == nil {
+			return nil, fmt.Errorf("%s: missing variable %s", name, format[m[4]:m[5]])
+		}
+		prefix, suffix := format[:m[0]], format[m[1]:]
+		for _, quantity := range general.PluralQuantities {
+			if value, ok := variable.values[quantity].(string); ok {
+				d.Set(general.PluralCode(name, quantity), prefix+value+suffix)
+			}
+		}
+	}
+	return d, nil
+}
+
+// WriteStringsdictFile writes plurals of the given dictionary to .stringsdict file at the given path
+func WriteStringsdictFile(path string, d *general.Dictionary) (file *os.File, err error) {
+	return general.CreateFile(path, []byte(formatStringsdict(d)))
+}
+
+// ReadStringsdictFile reads plurals from .stringsdict file at the given path
+func ReadStringsdictFile(path string) (d *general.Dictionary, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err = parseStringsdict(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return d, nil
+}
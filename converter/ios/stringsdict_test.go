@@ -0,0 +1,81 @@
+package ios
+
+import (
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatParseStringsdict(t *testing.T) {
+	d := general.DictionaryOf(
+		"apples#one", "%d apple",
+		"apples#other", "%d apples & pears",
+		"title", "Title",
+	)
+	data := formatStringsdict(d)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>apples</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>%#@value@</string>
+		<key>value</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>d</string>
+			<key>one</key>
+			<string>%d apple</string>
+			<key>other</key>
+			<string>%d apples &amp; pears</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`, data)
+
+	parsed, err := parseStringsdict([]byte(data))
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"apples#one", "%d apple",
+		"apples#other", "%d apples & pears",
+	), parsed)
+}
+
+func TestParseStringsdict(t *testing.T) {
+	parsed, err := parseStringsdict([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<plist version="1.0">
+<dict>
+	<key>files</key>
+	<dict>
+		<key>NSStringLocalizedFormatKey</key>
+		<string>Found %#@files@.</string>
+		<key>files</key>
+		<dict>
+			<key>NSStringFormatSpecTypeKey</key>
+			<string>NSStringPluralRuleType</string>
+			<key>NSStringFormatValueTypeKey</key>
+			<string>lu</string>
+			<key>other</key>
+			<string>%lu files</string>
+			<key>one</key>
+			<string>one file</string>
+		</dict>
+	</dict>
+</dict>
+</plist>
+`))
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"files#one", "Found one file.",
+		"files#other", "Found %lu files.",
+	), parsed)
+
+	_, err = parseStringsdict([]byte(`<plist><dict><key>files</key><dict></dict></dict></plist>`))
+	assert.EqualError(t, err, "files: missing variable in NSStringLocalizedFormatKey")
+}
@@ -0,0 +1,230 @@
+package ios
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+)
+
+const (
+	// XCStringsExtension defines the extension of Xcode string catalogs
+	XCStringsExtension = ".xcstrings"
+	// XCStringsVersion defines the version of written string catalogs
+	XCStringsVersion = "1.0"
+)
+
+// object defines JSON object of string catalog, fields, that are not known
+// to the converter, are kept as they are
+type object = map[string]interface{}
+
+// child returns the nested object with the given key, the object is created if create is set
+func child(o object, key string, create bool) object {
+	nested, ok := o[key].(object)
+	if !ok && create {
+		nested = object{}
+		o[key] = nested
+	}
+	return nested
+}
+
+// sortedKeys returns keys of the given object in alphabetical order, as Xcode writes them
+func sortedKeys(o object) (keys []string) {
+	for key := range o {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// setUnit sets the value and the state of stringUnit object, the state of unit with
+// the same value is kept unless the new state is given explicitly
+func setUnit(o object, value string, state string) {
+	unit := child(o, "stringUnit", true)
+	if old, _ := unit["value"].(string); old == value && state == "" {
+		return
+	}
+	if state == "" {
+		state = general.StateTranslated
+	}
+	unit["value"] = value
+	unit["state"] = state
+}
+
+// updateCatalog updates the string catalog with strings of the given set of dictionaries, strings
+// of the base language go to the source language, items of plurals are written as plural
+// variations, untranslatable strings are marked with "shouldTranslate": false
+func updateCatalog(catalog object, dicts general.Dictionaries, opts Options) {
+	srcLang, ok := catalog["sourceLanguage"].(string)
+	if !ok {
+		srcLang = general.SourceLanguage(opts.SourceLanguage, opts.BaseLanguage)
+		catalog["sourceLanguage"] = srcLang
+	}
+	if _, ok := catalog["version"]; !ok {
+		catalog["version"] = XCStringsVersion
+	}
+	strs := child(catalog, "strings", true)
+	attrs := dicts[general.TranslatableMeta]
+
+	for _, langCode := range general.SortedLanguages(dicts, opts.BaseLanguage) {
+		if general.IsMeta(langCode) {
+			continue
+		}
+		tag := srcLang
+		if langCode != opts.BaseLanguage {
+			tag = lprojTag(langCode)
+		}
+		states := dicts[general.StateMeta(langCode)]
+
+		d := dicts[langCode]
+		for _, code := range d.Codes() {
+			untranslatable := attrs.Get(code) == "false"
+			if untranslatable && langCode != opts.BaseLanguage {
+				continue
+			}
+
+			key := code
+			name, quantity, plural := general.ParsePluralCode(code)
+			if plural {
+				key = name
+			}
+
+			entry := child(strs, key, true)
+			if untranslatable {
+				entry["shouldTranslate"] = false
+			}
+			loc := child(child(entry, "localizations", true), tag, true)
+			if plural {
+				loc = child(child(child(loc, "variations", true), "plural", true), quantity, true)
+			}
+			setUnit(loc, ToIOSFormat(general.StripXLIFF(d.Get(code))), states.Get(code))
+		}
+	}
+}
+
+// readCatalog converts the string catalog to the set of dictionaries, translations with
+// states other than "translated" are listed in general.StateMeta dictionaries, strings
+// without localization to the source language get their keys as values
+func readCatalog(catalog object, opts Options) (dicts general.Dictionaries) {
+	dicts = make(general.Dictionaries)
+	srcLang, _ := catalog["sourceLanguage"].(string)
+	strs := child(catalog, "strings", false)
+
+	for _, key := range sortedKeys(strs) {
+		entry := child(strs, key, false)
+		if entry == nil {
+			continue
+		}
+		if shouldTranslate, ok := entry["shouldTranslate"].(bool); ok && !shouldTranslate {
+			general.LanguageDictionary(dicts, general.TranslatableMeta).Set(key, "false")
+		}
+
+		locs := child(entry, "localizations", false)
+		if _, ok := locs[srcLang]; !ok {
+			general.LanguageDictionary(dicts, opts.BaseLanguage).Set(key, ToAndroidFormat(key))
+		}
+
+		for _, tag := range sortedKeys(locs) {
+			langCode := opts.BaseLanguage
+			if tag != srcLang {
+				l, ok := general.ParseBCP47(tag)
+				if !ok {
+					continue
+				}
+				langCode = l.Qualifier()
+			}
+
+			set := func(code string, o object) {
+				unit := child(o, "stringUnit", false)
+				value, ok := unit["value"].(string)
+				if !ok {
+					return
+				}
+				general.LanguageDictionary(dicts, langCode).Set(code, ToAndroidFormat(value))
+				if state, _ := unit["state"].(string); state != general.StateTranslated && langCode != opts.BaseLanguage {
+					general.LanguageDictionary(dicts, general.StateMeta(langCode)).Set(code, state)
+				}
+			}
+
+			loc := child(locs, tag, false)
+			set(key, loc)
+			plurals := child(child(loc, "variations", false), "plural", false)
+			for _, quantity := range general.PluralQuantities {
+				if item := child(plurals, quantity, false); item != nil {
+					set(general.PluralCode(key, quantity), item)
+				}
+			}
+		}
+	}
+
+	return dicts
+}
+
+// keySeparatorRe matches separators between keys and values in indented JSON
+var keySeparatorRe = regexp.MustCompile(`(?m)^(\s*"(?:[^"\\]|\\.)*"): `)
+
+// marshalCatalog encodes the string catalog in the format of Xcode: keys are sorted,
+// objects are indented with two spaces and keys are separated from values with " : "
+func marshalCatalog(catalog object) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(catalog); err != nil {
+		return nil, err
+	}
+	return keySeparatorRe.ReplaceAll(bytes.TrimRight(buf.Bytes(), "\n"), []byte("$1 : ")), nil
+}
+
+// unmarshalCatalog decodes the string catalog, numbers are kept as they are
+func unmarshalCatalog(data []byte) (catalog object, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&catalog); err != nil {
+		return nil, err
+	}
+	return catalog, nil
+}
+
+// WriteXCStringsFile writes the given set of dictionaries to Xcode string catalog at the given path,
+// existing catalog is updated: values of strings are replaced, missing strings are added, other
+// strings and fields are left as is, states of translations are taken from general.StateMeta
+// dictionaries or set to "translated" for changed values
+func WriteXCStringsFile(path string, dicts general.Dictionaries, opts Options) (file *os.File, err error) {
+	catalog := object{}
+	if data, err := ioutil.ReadFile(path); err == nil {
+		if catalog, err = unmarshalCatalog(data); err != nil {
+			return nil, fmt.Errorf("%s: %v", path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	updateCatalog(catalog, dicts, opts)
+
+	data, err := marshalCatalog(catalog)
+	if err != nil {
+		return nil, err
+	}
+
+	return general.CreateFile(path, data)
+}
+
+// ReadXCStringsFile reads Xcode string catalog at the given path and converts it to the set
+// of dictionaries, strings of the source language are stored under the base language code,
+// other languages are stored under android locale qualifiers, e.g. "pt-rBR"
+func ReadXCStringsFile(path string, opts Options) (dicts general.Dictionaries, err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := unmarshalCatalog(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+	return readCatalog(catalog, opts), nil
+}
@@ -0,0 +1,151 @@
+package ios
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWriteXCStrings(t *testing.T) {
+	defer os.RemoveAll("/tmp/Localizable.xcstrings")
+	require.NoError(t, ioutil.WriteFile("/tmp/Localizable.xcstrings", []byte(`{
+  "sourceLanguage" : "en",
+  "strings" : {
+    "greeting" : {
+      "comment" : "Greeting on the main screen",
+      "localizations" : {
+        "de" : {
+          "stringUnit" : {
+            "state" : "needs_review",
+            "value" : "Hallo, %1$@!"
+          }
+        },
+        "en" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "Hello, %1$@!"
+          }
+        }
+      }
+    },
+    "OK" : {
+
+    }
+  },
+  "version" : "1.0"
+}`), 0644))
+
+	dicts, err := ReadXCStringsFile("/tmp/Localizable.xcstrings", Options{BaseLanguage: "default"})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"default": general.DictionaryOf(
+			"OK", "OK",
+			"greeting", "Hello, %1$s!",
+		),
+		"de": general.DictionaryOf(
+			"greeting", "Hallo, %1$s!",
+		),
+		general.StateMeta("de"): general.DictionaryOf(
+			"greeting", general.StateNeedsReview,
+		),
+	}, dicts)
+
+	_, err = WriteXCStringsFile("/tmp/Localizable.xcstrings", general.Dictionaries{
+		"default": general.DictionaryOf(
+			"greeting", "Hello, %1$s!",
+			"apples#one", "%d apple",
+			"apples#other", "%d apples",
+			"app_name", "App",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"greeting", "Olá, %1$s!",
+		),
+		general.TranslatableMeta: general.DictionaryOf(
+			"app_name", "false",
+		),
+	}, Options{BaseLanguage: "default"})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile("/tmp/Localizable.xcstrings")
+	require.NoError(t, err)
+	assert.Equal(t, `{
+  "sourceLanguage" : "en",
+  "strings" : {
+    "OK" : {},
+    "app_name" : {
+      "localizations" : {
+        "en" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "App"
+          }
+        }
+      },
+      "shouldTranslate" : false
+    },
+    "apples" : {
+      "localizations" : {
+        "en" : {
+          "variations" : {
+            "plural" : {
+              "one" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "%d apple"
+                }
+              },
+              "other" : {
+                "stringUnit" : {
+                  "state" : "translated",
+                  "value" : "%d apples"
+                }
+              }
+            }
+          }
+        }
+      }
+    },
+    "greeting" : {
+      "comment" : "Greeting on the main screen",
+      "localizations" : {
+        "de" : {
+          "stringUnit" : {
+            "state" : "needs_review",
+            "value" : "Hallo, %1$@!"
+          }
+        },
+        "en" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "Hello, %1$@!"
+          }
+        },
+        "pt-BR" : {
+          "stringUnit" : {
+            "state" : "translated",
+            "value" : "Olá, %1$@!"
+          }
+        }
+      }
+    }
+  },
+  "version" : "1.0"
+}`, string(data))
+
+	_, err = WriteXCStringsFile("/tmp/Localizable.xcstrings", general.Dictionaries{
+		"default":               general.DictionaryOf("greeting", "Hello, %1$s!"),
+		"de":                    general.DictionaryOf("greeting", "Hallo, %1$s!"),
+		general.StateMeta("de"): general.DictionaryOf("greeting", general.StateTranslated),
+	}, Options{BaseLanguage: "default"})
+	require.NoError(t, err)
+
+	dicts, err = ReadXCStringsFile("/tmp/Localizable.xcstrings", Options{BaseLanguage: "default"})
+	require.NoError(t, err)
+	assert.Nil(t, dicts[general.StateMeta("de")])
+	assert.Equal(t, "%d apple", dicts["default"].Get("apples#one"))
+	assert.Equal(t, "%d apples", dicts["default"].Get("apples#other"))
+}
@@ -0,0 +1,205 @@
+package po
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// message defines a single entry of PO file
+type message struct {
+	comments []string // extracted comments for translators, "#." lines
+	flags    []string // flags of entry, e.g. "fuzzy", "#," lines
+	context  string   // msgctxt
+	id       string   // msgid, the source string
+	str      string   // msgstr, the translation
+}
+
+// fuzzy reports whether the message is marked with "fuzzy" flag
+func (m message) fuzzy() bool {
+	for _, flag := range m.flags {
+		if flag == FuzzyFlag {
+			return true
+		}
+	}
+	return false
+}
+
+// quote formats the given string as PO string, strings with new lines are split
+// into several lines after each new line, e.g. msgid ""\n"first\n"\n"second"
+func quote(keyword string, s string) string {
+	escaped := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\t", `\t`, "\r", `\r`).Replace(s)
+	lines := strings.SplitAfter(escaped, "\n")
+	if len(lines) == 1 || (len(lines) == 2 && lines[1] == "") {
+		return keyword + ` "` + strings.Replace(escaped, "\n", `\n`, -1) + `"` + "\n"
+	}
+
+	var b strings.Builder
+	b.WriteString(keyword + ` ""` + "\n")
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		b.WriteString(`"` + strings.Replace(line, "\n", `\n`, -1) + `"` + "\n")
+	}
+	return b.String()
+}
+
+// format returns the message in PO format
+func (m message) format() string {
+	var b strings.Builder
+	for _, comment := range m.comments {
+		for _, line := range strings.Split(comment, "\n") {
+			b.WriteString(strings.TrimRight("#. "+line, " ") + "\n")
+		}
+	}
+	if len(m.flags) > 0 {
+		b.WriteString("#, " + strings.Join(m.flags, ", ") + "\n")
+	}
+	if m.context != "" {
+		b.WriteString(quote("msgctxt", m.context))
+	}
+	b.WriteString(quote("msgid", m.id))
+	b.WriteString(quote("msgstr", m.str))
+	return b.String()
+}
+
+// formatCatalog returns the content of PO file with the given header fields and messages
+func formatCatalog(header []string, messages []message) string {
+	var b strings.Builder
+	b.WriteString(message{id: "", str: strings.Join(header, "\n") + "\n"}.format())
+	for _, m := range messages {
+		b.WriteString("\n" + m.format())
+	}
+	return b.String()
+}
+
+// unquote parses PO string in double quotes
+func unquote(s string) (string, error) {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return "", fmt.Errorf("expected string in double quotes")
+	}
+	var b strings.Builder
+	s = s[1 : len(s)-1]
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'r':
+			b.WriteByte('\r')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// parseCatalog parses the content of PO file, obsolete entries ("#~") are skipped, entries with
+// plural forms are read with the first form of translation, the header entry is returned separately
+func parseCatalog(data string) (header map[string]string, messages []message, err error) {
+	header = map[string]string{}
+
+	var (
+		m       message
+		field   *string // field of the message, that is continued by the following strings
+		started bool    // whether the message has any keyword
+		last    string  // the last keyword of the message
+	)
+
+	flush := func() {
+		if !started {
+			m = message{}
+			return
+		}
+		if m.id == "" && m.context == "" {
+			for _, line := range strings.Split(m.str, "\n") {
+				if i := strings.Index(line, ":"); i > 0 {
+					header[strings.TrimSpace(line[:i])] = strings.TrimSpace(line[i+1:])
+				}
+			}
+		} else {
+			messages = append(messages, m)
+		}
+		m, field, started, last = message{}, nil, false, ""
+	}
+
+	skip := new(string) // sink for fields, that are not kept, e.g. msgid_plural or msgstr[1]
+
+	for n, line := range strings.Split(strings.TrimPrefix(data, "\uFEFF"), "\n") {
+		line = strings.TrimSpace(line)
+		errorf := func(err error) error { return fmt.Errorf("line %d: %v", n+1, err) }
+
+		switch {
+		case line == "":
+			continue
+		case strings.HasPrefix(line, "#"):
+			if started {
+				flush()
+			}
+			switch {
+			case strings.HasPrefix(line, "#."):
+				m.comments = append(m.comments, strings.TrimSpace(line[2:]))
+			case strings.HasPrefix(line, "#,"):
+				for _, flag := range strings.Split(line[2:], ",") {
+					if flag = strings.TrimSpace(flag); flag != "" {
+						m.flags = append(m.flags, flag)
+					}
+				}
+			}
+			continue
+		case strings.HasPrefix(line, `"`):
+			if field == nil {
+				return nil, nil, errorf(fmt.Errorf("unexpected string"))
+			}
+			s, err := unquote(line)
+			if err != nil {
+				return nil, nil, errorf(err)
+			}
+			*field += s
+			continue
+		}
+
+		i := strings.IndexAny(line, " \t")
+		if i < 0 {
+			return nil, nil, errorf(fmt.Errorf("expected keyword and string"))
+		}
+		keyword, value := line[:i], strings.TrimSpace(line[i:])
+
+		switch {
+		case keyword == "msgctxt" || (keyword == "msgid" && last != "msgctxt"):
+			if started {
+				flush()
+			}
+			field = &m.id
+			if keyword == "msgctxt" {
+				field = &m.context
+			}
+		case keyword == "msgid":
+			field = &m.id
+		case keyword == "msgstr" || keyword == "msgstr[0]":
+			field = &m.str
+		case keyword == "msgid_plural" || strings.HasPrefix(keyword, "msgstr["):
+			*skip = ""
+			field = skip
+		default:
+			return nil, nil, errorf(fmt.Errorf("unknown keyword %s", strconv.Quote(keyword)))
+		}
+		started, last = true, keyword
+
+		s, err := unquote(value)
+		if err != nil {
+			return nil, nil, errorf(err)
+		}
+		*field += s
+	}
+	flush()
+
+	return header, messages, nil
+}
@@ -0,0 +1,78 @@
+package po
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatMessage(t *testing.T) {
+	assert.Equal(t, `#. Title of the main screen
+#, fuzzy
+msgctxt "title"
+msgid "Say \"hi\""
+msgstr "Sag \"hallo\""
+`, message{
+		comments: []string{"Title of the main screen"},
+		flags:    []string{FuzzyFlag},
+		context:  "title",
+		id:       `Say "hi"`,
+		str:      `Sag "hallo"`,
+	}.format())
+
+	assert.Equal(t, `msgctxt "lines"
+msgid ""
+"First\n"
+"Second"
+msgstr "Single\n"
+`, message{context: "lines", id: "First\nSecond", str: "Single\n"}.format())
+}
+
+func TestParseCatalog(t *testing.T) {
+	header, messages, err := parseCatalog(`# Translator comment
+msgid ""
+msgstr ""
+"Language: pt_BR\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#. Title of the main screen
+#: app/src/main/res/values/strings.xml
+#, fuzzy, c-format
+msgctxt "title"
+msgid "Title"
+msgstr "Título"
+
+msgctxt "lines"
+msgid ""
+"First\n"
+"Second"
+msgstr "Primeira\nSegunda"
+msgid "no context"
+msgstr "sem contexto"
+
+msgctxt "apples"
+msgid "%d apple"
+msgid_plural "%d apples"
+msgstr[0] "%d maçã"
+msgstr[1] "%d maçãs"
+
+#~ msgctxt "obsolete"
+#~ msgid "Obsolete"
+#~ msgstr "Obsoleto"
+`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"Language":     "pt_BR",
+		"Content-Type": "text/plain; charset=UTF-8",
+	}, header)
+	assert.Equal(t, []message{
+		{comments: []string{"Title of the main screen"}, flags: []string{"fuzzy", "c-format"}, context: "title", id: "Title", str: "Título"},
+		{context: "lines", id: "First\nSecond", str: "Primeira\nSegunda"},
+		{id: "no context", str: "sem contexto"},
+		{context: "apples", id: "%d apple", str: "%d maçã"},
+	}, messages)
+
+	_, _, err = parseCatalog("msgid \"title\"\nmsgstr title")
+	assert.EqualError(t, err, "line 2: expected string in double quotes")
+}
@@ -0,0 +1,181 @@
+// Package po specifies functions and structs for writing dictionaries to gettext
+// .pot template and .po files, one file per language, and reading them back
+package po
+
+import (
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// TemplateFilename defines the name of .pot template with strings of the base language
+	TemplateFilename = "messages.pot"
+	// Extension defines the extension of files with translations
+	Extension = ".po"
+	// FuzzyFlag defines the flag of translations, that have to be reviewed
+	FuzzyFlag = "fuzzy"
+)
+
+// Options defines the options of writing and reading PO files
+type Options struct {
+	// BaseLanguage defines the language code of source strings
+	BaseLanguage string
+	// IncludeFuzzy defines whether to read translations marked with "fuzzy" flag,
+	// they are listed in general.StateMeta dictionaries as "needs_review",
+	// otherwise they are skipped as gettext does
+	IncludeFuzzy bool
+}
+
+// poLanguage returns the gettext language code of the given language, e.g. "pt_BR"
+func poLanguage(langCode string) string {
+	if l, ok := general.ParseLocale(langCode); ok {
+		return strings.Replace(l.BCP47(), "-", "_", -1)
+	}
+	return langCode
+}
+
+// header returns fields of the header entry of PO file for the given language,
+// the language is omitted for .pot template
+func header(langCode string) (fields []string) {
+	fields = []string{
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"Content-Transfer-Encoding: 8bit",
+	}
+	if langCode != "" {
+		fields = append(fields, "Language: "+poLanguage(langCode))
+	}
+	return fields
+}
+
+// makeMessages makes messages with translations of strings of the base language to the given
+// language, codes of strings are kept in msgctxt, descriptions are written as extracted comments,
+// translations with "needs_review" state are marked with "fuzzy" flag, untranslatable strings
+// are skipped
+func makeMessages(dicts general.Dictionaries, langCode string, baseLang string) (messages []message) {
+	base := dicts[baseLang]
+	target := dicts[langCode]
+	attrs := dicts[general.TranslatableMeta]
+	descriptions := dicts[general.DescriptionMeta]
+	states := dicts[general.StateMeta(langCode)]
+
+	for _, code := range base.Codes() {
+		if attrs.Get(code) == "false" {
+			continue
+		}
+		m := message{context: code, id: base.Get(code), str: target.Get(code)}
+		if description, ok := descriptions.Lookup(code); ok {
+			m.comments = []string{description}
+		}
+		if m.str != "" && states.Get(code) == general.StateNeedsReview {
+			m.flags = []string{FuzzyFlag}
+		}
+		messages = append(messages, m)
+	}
+	return messages
+}
+
+// WritePOFolder writes the .pot template with strings of the base language and .po file for each
+// other language to the folder at the given path, .po files are named after gettext language
+// codes, e.g. "pt_BR.po"
+func WritePOFolder(path string, dicts general.Dictionaries, opts Options) (files []*os.File, err error) {
+	err = os.MkdirAll(path, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	files = []*os.File{}
+
+	var file *os.File
+	file, err = general.CreateFile(filepath.Join(path, TemplateFilename),
+		[]byte(formatCatalog(header(""), makeMessages(dicts, "", opts.BaseLanguage))))
+	if file != nil {
+		files = append(files, file)
+	}
+	if err != nil {
+		return
+	}
+
+	for _, langCode := range general.SortedLanguages(dicts, opts.BaseLanguage) {
+		if langCode == opts.BaseLanguage || general.IsMeta(langCode) {
+			continue
+		}
+		file, err = general.CreateFile(filepath.Join(path, poLanguage(langCode)+Extension),
+			[]byte(formatCatalog(header(langCode), makeMessages(dicts, langCode, opts.BaseLanguage))))
+		if file != nil {
+			files = append(files, file)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return files, nil
+}
+
+// ReadPOFile reads .po file and adds its messages to the given set of dictionaries, source strings are
+// added to the base language, translations are added to the language from the "Language" header or
+// from the name of file, that is converted to android locale qualifier, e.g. "pt-rBR", messages
+// without msgctxt are skipped
+func ReadPOFile(path string, dicts general.Dictionaries, opts Options) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	hdr, messages, err := parseCatalog(string(data))
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	langCode := hdr["Language"]
+	if langCode == "" {
+		langCode = strings.TrimSuffix(filepath.Base(path), Extension)
+	}
+	if l, ok := general.ParseBCP47(strings.Replace(langCode, "_", "-", -1)); ok {
+		langCode = l.Qualifier()
+	}
+
+	for _, m := range messages {
+		if m.context == "" {
+			continue
+		}
+		general.LanguageDictionary(dicts, opts.BaseLanguage).Set(m.context, m.id)
+		if len(m.comments) > 0 {
+			general.LanguageDictionary(dicts, general.DescriptionMeta).Set(m.context, strings.Join(m.comments, "\n"))
+		}
+		if m.str == "" || (m.fuzzy() && !opts.IncludeFuzzy) {
+			continue
+		}
+		general.LanguageDictionary(dicts, langCode).Set(m.context, m.str)
+		if m.fuzzy() {
+			general.LanguageDictionary(dicts, general.StateMeta(langCode)).Set(m.context, general.StateNeedsReview)
+		}
+	}
+	return nil
+}
+
+// ReadPOFolder reads all .po files in the folder at the given path and converts them to the
+// set of dictionaries, the .pot template is not read as .po files contain source strings too
+func ReadPOFolder(path string, opts Options) (dicts general.Dictionaries, err error) {
+	contents, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dicts = make(general.Dictionaries)
+
+	for _, entry := range contents {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != Extension {
+			continue
+		}
+		if err = ReadPOFile(filepath.Join(path, entry.Name()), dicts, opts); err != nil {
+			return nil, err
+		}
+	}
+
+	return dicts, nil
+}
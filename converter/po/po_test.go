@@ -0,0 +1,100 @@
+package po
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWritePO(t *testing.T) {
+	defer os.RemoveAll("/tmp/po")
+	dicts := general.Dictionaries{
+		"en": general.DictionaryOf(
+			"title", "Title",
+			"greeting", "Hello, %1$s!",
+			"app_name", "App",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"title", "Título",
+			"greeting", "Olá, %1$s!",
+		),
+		general.DescriptionMeta: general.DictionaryOf(
+			"title", "Title of the main screen",
+		),
+		general.StateMeta("pt-rBR"): general.DictionaryOf(
+			"greeting", general.StateNeedsReview,
+		),
+		general.TranslatableMeta: general.DictionaryOf(
+			"app_name", "false",
+		),
+	}
+	_, err := WritePOFolder("/tmp/po", dicts, Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile("/tmp/po/messages.pot")
+	require.NoError(t, err)
+	assert.Equal(t, `msgid ""
+msgstr ""
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+
+#. Title of the main screen
+msgctxt "title"
+msgid "Title"
+msgstr ""
+
+msgctxt "greeting"
+msgid "Hello, %1$s!"
+msgstr ""
+`, string(data))
+
+	data, err = ioutil.ReadFile("/tmp/po/pt_BR.po")
+	require.NoError(t, err)
+	assert.Equal(t, `msgid ""
+msgstr ""
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Language: pt_BR\n"
+
+#. Title of the main screen
+msgctxt "title"
+msgid "Title"
+msgstr "Título"
+
+#, fuzzy
+msgctxt "greeting"
+msgid "Hello, %1$s!"
+msgstr "Olá, %1$s!"
+`, string(data))
+
+	read, err := ReadPOFolder("/tmp/po", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"title", "Title",
+			"greeting", "Hello, %1$s!",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"title", "Título",
+		),
+		general.DescriptionMeta: general.DictionaryOf(
+			"title", "Title of the main screen",
+		),
+	}, read)
+
+	read, err = ReadPOFolder("/tmp/po", Options{BaseLanguage: "en", IncludeFuzzy: true})
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"title", "Título",
+		"greeting", "Olá, %1$s!",
+	), read["pt-rBR"])
+	assert.Equal(t, general.DictionaryOf(
+		"greeting", general.StateNeedsReview,
+	), read[general.StateMeta("pt-rBR")])
+}
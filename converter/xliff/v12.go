@@ -0,0 +1,104 @@
+package xliff
+
+import (
+	"encoding/xml"
+)
+
+// Namespace12 defines the namespace of XLIFF 1.2 documents
+const Namespace12 = "urn:oasis:names:tc:xliff:document:1.2"
+
+// xliff12 defines the root <xliff></xliff> tag of XLIFF 1.2 document
+type xliff12 struct {
+	XMLName xml.Name `xml:"xliff"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Version string   `xml:"version,attr"`
+	Files   []file12 `xml:"file"`
+}
+
+// file12 defines <file></file> tag with strings from a single xml file
+type file12 struct {
+	Original       string        `xml:"original,attr"`
+	SourceLanguage string        `xml:"source-language,attr"`
+	TargetLanguage string        `xml:"target-language,attr,omitempty"`
+	Datatype       string        `xml:"datatype,attr"`
+	Units          []transUnit12 `xml:"body>trans-unit"`
+}
+
+// transUnit12 defines <trans-unit></trans-unit> tag with a single string
+type transUnit12 struct {
+	ID        string    `xml:"id,attr"`
+	Resname   string    `xml:"resname,attr,omitempty"`
+	Translate string    `xml:"translate,attr,omitempty"` // "no" for untranslatable strings
+	Source    text      `xml:"source"`
+	Target    *target12 `xml:"target"`
+}
+
+// target12 defines <target></target> tag with translation
+type target12 struct {
+	State string `xml:"state,attr,omitempty"`
+	text
+}
+
+// UnmarshalXML decodes <target></target> tag, its state is not read
+func (t *target12) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	return t.text.UnmarshalXML(d, start)
+}
+
+// marshal12 converts the document to XLIFF 1.2 structure
+func marshal12(doc document) interface{} {
+	res := xliff12{Xmlns: Namespace12, Version: Version12}
+	for _, f := range doc.files {
+		f12 := file12{
+			Original:       f.original,
+			SourceLanguage: doc.srcLang,
+			TargetLanguage: doc.trgLang,
+			Datatype:       "plaintext",
+		}
+		for _, u := range f.units {
+			tu := transUnit12{ID: u.id, Resname: u.id, Source: text{Value: u.source}}
+			if !u.translatable {
+				tu.Translate = "no"
+			}
+			if u.target != "" {
+				tu.Target = &target12{State: "translated", text: text{Value: u.target}}
+			}
+			f12.Units = append(f12.Units, tu)
+		}
+		res.Files = append(res.Files, f12)
+	}
+	return res
+}
+
+// unmarshal12 parses XLIFF 1.2 document, units with inline elements, that can't be
+// converted to text, are reported as errors (see decodeText)
+func unmarshal12(data []byte) (doc document, err error) {
+	var x xliff12
+	if err = xml.Unmarshal(data, &x); err != nil {
+		return doc, err
+	}
+	for _, f12 := range x.Files {
+		doc.srcLang, doc.trgLang = f12.SourceLanguage, f12.TargetLanguage
+		f := file{original: f12.Original}
+		if f.original == "" {
+			f.original = DefaultFile
+		}
+		for _, tu := range f12.Units {
+			u := unit{id: tu.ID, source: tu.Source.Value, translatable: tu.Translate != "no"}
+			if tu.Resname != "" {
+				u.id = tu.Resname
+			}
+			if err = tu.Source.check(u.id); err != nil {
+				return doc, err
+			}
+			if tu.Target != nil {
+				if err = tu.Target.check(u.id); err != nil {
+					return doc, err
+				}
+				u.target = tu.Target.Value
+			}
+			f.units = append(f.units, u)
+		}
+		doc.files = append(doc.files, f)
+	}
+	return doc, nil
+}
@@ -0,0 +1,107 @@
+package xliff
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+)
+
+// Namespace20 defines the namespace of XLIFF 2.0 documents
+const Namespace20 = "urn:oasis:names:tc:xliff:document:2.0"
+
+// xliff20 defines the root <xliff></xliff> tag of XLIFF 2.0 document
+type xliff20 struct {
+	XMLName xml.Name `xml:"xliff"`
+	Xmlns   string   `xml:"xmlns,attr,omitempty"`
+	Version string   `xml:"version,attr"`
+	SrcLang string   `xml:"srcLang,attr"`
+	TrgLang string   `xml:"trgLang,attr,omitempty"`
+	Files   []file20 `xml:"file"`
+}
+
+// file20 defines <file></file> tag with strings from a single xml file
+type file20 struct {
+	ID       string   `xml:"id,attr"`
+	Original string   `xml:"original,attr,omitempty"`
+	Units    []unit20 `xml:"unit"`
+}
+
+// unit20 defines <unit></unit> tag with a single string, ids of units must be NMTOKENs,
+// so codes of strings are kept in the name attribute
+type unit20 struct {
+	ID        string      `xml:"id,attr"`
+	Name      string      `xml:"name,attr,omitempty"`
+	Translate string      `xml:"translate,attr,omitempty"` // "no" for untranslatable strings
+	Segments  []segment20 `xml:"segment"`
+}
+
+// segment20 defines <segment></segment> tag with source and translation
+type segment20 struct {
+	State  string `xml:"state,attr,omitempty"`
+	Source text   `xml:"source"`
+	Target *text  `xml:"target"`
+}
+
+// marshal20 converts the document to XLIFF 2.0 structure
+func marshal20(doc document) interface{} {
+	res := xliff20{Xmlns: Namespace20, Version: Version20, SrcLang: doc.srcLang, TrgLang: doc.trgLang}
+	n := 0
+	for i, f := range doc.files {
+		f20 := file20{ID: "f" + strconv.Itoa(i+1), Original: f.original}
+		for _, u := range f.units {
+			n++
+			u20 := unit20{ID: "u" + strconv.Itoa(n), Name: u.id}
+			if !u.translatable {
+				u20.Translate = "no"
+			}
+			segment := segment20{State: "initial", Source: text{Value: u.source}}
+			if u.target != "" {
+				segment.State = "translated"
+				segment.Target = &text{Value: u.target}
+			}
+			u20.Segments = []segment20{segment}
+			f20.Units = append(f20.Units, u20)
+		}
+		res.Files = append(res.Files, f20)
+	}
+	return res
+}
+
+// unmarshal20 parses XLIFF 2.0 document, sources and targets of all segments of unit are joined,
+// units with inline elements, that can't be converted to text, are reported as errors (see decodeText)
+func unmarshal20(data []byte) (doc document, err error) {
+	var x xliff20
+	if err = xml.Unmarshal(data, &x); err != nil {
+		return doc, err
+	}
+	doc.srcLang, doc.trgLang = x.SrcLang, x.TrgLang
+	for _, f20 := range x.Files {
+		f := file{original: f20.Original}
+		if f.original == "" {
+			f.original = DefaultFile
+		}
+		for _, u20 := range f20.Units {
+			u := unit{id: u20.ID, translatable: u20.Translate != "no"}
+			if u20.Name != "" {
+				u.id = u20.Name
+			}
+			var source, target strings.Builder
+			for _, segment := range u20.Segments {
+				if err = segment.Source.check(u.id); err != nil {
+					return doc, err
+				}
+				if err = segment.Target.check(u.id); err != nil {
+					return doc, err
+				}
+				source.WriteString(segment.Source.Value)
+				if segment.Target != nil {
+					target.WriteString(segment.Target.Value)
+				}
+			}
+			u.source, u.target = source.String(), target.String()
+			f.units = append(f.units, u)
+		}
+		doc.files = append(doc.files, f)
+	}
+	return doc, nil
+}
@@ -0,0 +1,311 @@
+// Package xliff specifies functions and structs for writing dictionaries
+// to XLIFF files, one file per target language, and reading them back
+package xliff
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Version12 defines the version 1.2 of XLIFF format
+	Version12 = "1.2"
+	// Version20 defines the version 2.0 of XLIFF format
+	Version20 = "2.0"
+	// Extension defines the extension of written XLIFF files
+	Extension = ".xlf"
+	// DefaultFile defines the name of file of strings, that are not listed in general.FileMeta
+	DefaultFile = "strings.xml"
+	// UndeterminedLanguage defines the BCP-47 tag of the base language, that is not a locale
+	UndeterminedLanguage = "und"
+)
+
+// Options defines the options of writing and reading XLIFF files
+type Options struct {
+	// BaseLanguage defines the language code of source strings
+	BaseLanguage string
+	// Version defines the version of written XLIFF files, Version12 or Version20,
+	// files of both versions are read regardless of it
+	Version string
+}
+
+// unit defines a single translatable string
+type unit struct {
+	id           string // code of string
+	source       string // string of the base language
+	target       string // translation, empty if it is missing
+	translatable bool   // false for strings marked with translatable="false"
+}
+
+// file defines strings from a single xml file of values folders
+type file struct {
+	original string // name of xml file, e.g. "strings.xml"
+	units    []unit
+}
+
+// document defines the content of XLIFF file independently from its version
+type document struct {
+	srcLang string // BCP-47 tag of the source language
+	trgLang string // BCP-47 tag of the target language
+	files   []file
+}
+
+// text defines the content of <source> and <target> tags, inline elements are read as text
+// (see decodeText), the first element, that can't be read, is kept to report the error
+type text struct {
+	Value       string `xml:",chardata"`
+	unsupported string // name of inline element, that can't be converted to android string
+}
+
+// UnmarshalXML decodes the content of tag with inline elements
+func (t *text) UnmarshalXML(d *xml.Decoder, start xml.StartElement) (err error) {
+	t.Value, t.unsupported, err = decodeText(d)
+	return err
+}
+
+// check returns the error about unsupported inline element of the unit with the given id
+func (t *text) check(id string) error {
+	if t == nil || t.unsupported == "" {
+		return nil
+	}
+	return fmt.Errorf("unit %s: unsupported inline element <%s>", id, t.unsupported)
+}
+
+// decodeText decodes the content of the current element as text: annotations (<mrk>) are replaced
+// with their content and native codes of XLIFF 1.2 (<ph>, <bpt>, <ept> and <it>) are replaced with
+// the codes, e.g. "<b>", other inline elements, e.g. <g>, <x/> or <pc>, refer to markup, that is
+// unknown to the converter, so the name of the first of them is returned as unsupported
+func decodeText(d *xml.Decoder) (value string, unsupported string, err error) {
+	var b strings.Builder
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return "", "", err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			b.Write(t)
+		case xml.StartElement:
+			name := t.Name.Local
+			switch name {
+			case "mrk", "ph", "bpt", "ept", "it":
+				inner, innerUnsupported, err := decodeText(d)
+				if err != nil {
+					return "", "", err
+				}
+				if unsupported == "" {
+					unsupported = innerUnsupported
+				}
+				if inner == "" && name != "mrk" && unsupported == "" {
+					// native code is stored outside of the element, e.g. <ph id="1" dataRef="d1"/>
+					unsupported = name
+				}
+				b.WriteString(inner)
+			default:
+				if unsupported == "" {
+					unsupported = name
+				}
+				if err = d.Skip(); err != nil {
+					return "", "", err
+				}
+			}
+		case xml.EndElement:
+			return b.String(), unsupported, nil
+		}
+	}
+}
+
+// bcp47Tag returns the BCP-47 tag of the given language code
+func bcp47Tag(langCode string) string {
+	if l, ok := general.ParseLocale(langCode); ok {
+		return l.BCP47()
+	}
+	return UndeterminedLanguage
+}
+
+// makeDocument makes the document with translations of the base language strings to the given language,
+// strings are grouped by xml files from the general.FileMeta dictionary
+func makeDocument(dicts general.Dictionaries, langCode string, baseLang string) (doc document) {
+	doc = document{srcLang: bcp47Tag(baseLang), trgLang: bcp47Tag(langCode)}
+
+	base := dicts[baseLang]
+	target := dicts[langCode]
+	attrs := dicts[general.TranslatableMeta]
+	filenames := dicts[general.FileMeta]
+
+	fileIndexes := map[string]int{}
+	for _, code := range base.Codes() {
+		original := DefaultFile
+		if filename, ok := filenames.Lookup(code); ok {
+			original = filename
+		}
+		i, ok := fileIndexes[original]
+		if !ok {
+			i = len(doc.files)
+			fileIndexes[original] = i
+			doc.files = append(doc.files, file{original: original})
+		}
+		doc.files[i].units = append(doc.files[i].units, unit{
+			id:           code,
+			source:       base.Get(code),
+			target:       target.Get(code),
+			translatable: attrs.Get(code) != "false",
+		})
+	}
+	return doc
+}
+
+// addToDictionaries adds strings of the document to the given set of dictionaries, sources are
+// added to the base language and targets are added to the language from the target language tag,
+// that is required if the document has any targets
+func (doc document) addToDictionaries(dicts general.Dictionaries, baseLang string) error {
+	for _, f := range doc.files {
+		for _, u := range f.units {
+			if u.target != "" && doc.trgLang == "" {
+				return fmt.Errorf("missing target language of translations")
+			}
+		}
+	}
+
+	langCode := doc.trgLang
+	if l, ok := general.ParseBCP47(langCode); ok {
+		langCode = l.Qualifier()
+	}
+
+	for _, f := range doc.files {
+		for _, u := range f.units {
+			general.LanguageDictionary(dicts, baseLang).Set(u.id, u.source)
+			if u.target != "" {
+				general.LanguageDictionary(dicts, langCode).Set(u.id, u.target)
+			}
+			if !u.translatable {
+				general.LanguageDictionary(dicts, general.TranslatableMeta).Set(u.id, "false")
+			}
+			if f.original != DefaultFile {
+				general.LanguageDictionary(dicts, general.FileMeta).Set(u.id, f.original)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteXLIFFFolder writes translations of strings of the base language to the folder at the given
+// path, one XLIFF file per target language, files are named after BCP-47 tags of target languages,
+// e.g. "pt-BR.xlf", strings from xml files listed in the general.FileMeta dictionary are written to
+// separate <file> elements, untranslatable strings are marked with translate="no", inline
+// markup of strings, e.g. <b> or <xliff:g>, is written as text
+func WriteXLIFFFolder(path string, dicts general.Dictionaries, opts Options) (files []*os.File, err error) {
+	marshal := marshal12
+	switch opts.Version {
+	case Version12, "":
+	case Version20:
+		marshal = marshal20
+	default:
+		return nil, fmt.Errorf("unsupported XLIFF version %q", opts.Version)
+	}
+
+	err = os.MkdirAll(path, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	files = []*os.File{}
+
+	for _, langCode := range general.SortedLanguages(dicts, opts.BaseLanguage) {
+		if langCode == opts.BaseLanguage || general.IsMeta(langCode) {
+			continue
+		}
+
+		doc := makeDocument(dicts, langCode, opts.BaseLanguage)
+
+		var data []byte
+		data, err = xml.MarshalIndent(marshal(doc), "", "	")
+		if err != nil {
+			return
+		}
+
+		filePath := filepath.Join(path, doc.trgLang+Extension)
+		if doc.trgLang == UndeterminedLanguage {
+			filePath = filepath.Join(path, langCode+Extension)
+		}
+
+		var f *os.File
+		f, err = general.CreateFile(filePath, []byte(xml.Header+string(data)+"\n"))
+		if f != nil {
+			files = append(files, f)
+		}
+		if err != nil {
+			return
+		}
+	}
+
+	return files, nil
+}
+
+// versionEntry defines the root <xliff></xliff> tag with the version attribute only
+type versionEntry struct {
+	Version string `xml:"version,attr"`
+}
+
+// ReadXLIFFFile reads XLIFF file of version 1.2 or 2.0 and adds its strings to the given set of
+// dictionaries, sources are added to the base language, targets are added to the language of
+// target language tag, that is converted to android locale qualifier, e.g. "pt-rBR", units with inline
+// elements, that refer to unknown markup, e.g. <g>, and translations without target language are errors
+func ReadXLIFFFile(path string, dicts general.Dictionaries, opts Options) (err error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var v versionEntry
+	if err = xml.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	var doc document
+	switch {
+	case v.Version == Version12:
+		doc, err = unmarshal12(data)
+	case strings.HasPrefix(v.Version, "2."):
+		doc, err = unmarshal20(data)
+	default:
+		return fmt.Errorf("%s: unsupported XLIFF version %q", path, v.Version)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	if err = doc.addToDictionaries(dicts, opts.BaseLanguage); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// ReadXLIFFFolder reads all XLIFF files (*.xlf and *.xliff) in the folder at the given path
+// and converts them to the set of dictionaries
+func ReadXLIFFFolder(path string, opts Options) (dicts general.Dictionaries, err error) {
+	contents, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dicts = make(general.Dictionaries)
+
+	for _, entry := range contents {
+		ext := filepath.Ext(entry.Name())
+		if entry.IsDir() || (ext != Extension && ext != ".xliff") {
+			continue
+		}
+		err = ReadXLIFFFile(filepath.Join(path, entry.Name()), dicts, opts)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return dicts, nil
+}
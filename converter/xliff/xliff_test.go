@@ -0,0 +1,194 @@
+package xliff
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testDictionaries() general.Dictionaries {
+	return general.Dictionaries{
+		"en": general.DictionaryOf(
+			"title", "Title",
+			"apples#other", "%d apples & pears",
+			"app_name", "App",
+			"error", "Error",
+		),
+		"de": general.DictionaryOf(
+			"title", "Titel",
+			"apples#other", "%d Äpfel & Birnen",
+			"error", "Fehler",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"title", "Título",
+		),
+		general.TranslatableMeta: general.DictionaryOf(
+			"app_name", "false",
+		),
+		general.FileMeta: general.DictionaryOf(
+			"error", "errors.xml",
+		),
+	}
+}
+
+func TestReadWriteXLIFF12(t *testing.T) {
+	defer os.RemoveAll("/tmp/xliff")
+	_, err := WriteXLIFFFolder("/tmp/xliff", testDictionaries(), Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.FileExists(t, "/tmp/xliff/de.xlf")
+	assert.FileExists(t, "/tmp/xliff/pt-BR.xlf")
+
+	data, err := ioutil.ReadFile("/tmp/xliff/pt-BR.xlf")
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">
+	<file original="strings.xml" source-language="en" target-language="pt-BR" datatype="plaintext">
+		<body>
+			<trans-unit id="title" resname="title">
+				<source>Title</source>
+				<target state="translated">Título</target>
+			</trans-unit>
+			<trans-unit id="apples#other" resname="apples#other">
+				<source>%d apples &amp; pears</source>
+			</trans-unit>
+			<trans-unit id="app_name" resname="app_name" translate="no">
+				<source>App</source>
+			</trans-unit>
+		</body>
+	</file>
+	<file original="errors.xml" source-language="en" target-language="pt-BR" datatype="plaintext">
+		<body>
+			<trans-unit id="error" resname="error">
+				<source>Error</source>
+			</trans-unit>
+		</body>
+	</file>
+</xliff>
+`, string(data))
+
+	dicts, err := ReadXLIFFFolder("/tmp/xliff", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, testDictionaries(), dicts)
+}
+
+func TestReadWriteXLIFF20(t *testing.T) {
+	defer os.RemoveAll("/tmp/xliff")
+	_, err := WriteXLIFFFolder("/tmp/xliff", testDictionaries(), Options{BaseLanguage: "en", Version: Version20})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile("/tmp/xliff/pt-BR.xlf")
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="pt-BR">
+	<file id="f1" original="strings.xml">
+		<unit id="u1" name="title">
+			<segment state="translated">
+				<source>Title</source>
+				<target>Título</target>
+			</segment>
+		</unit>
+		<unit id="u2" name="apples#other">
+			<segment state="initial">
+				<source>%d apples &amp; pears</source>
+			</segment>
+		</unit>
+		<unit id="u3" name="app_name" translate="no">
+			<segment state="initial">
+				<source>App</source>
+			</segment>
+		</unit>
+	</file>
+	<file id="f2" original="errors.xml">
+		<unit id="u4" name="error">
+			<segment state="initial">
+				<source>Error</source>
+			</segment>
+		</unit>
+	</file>
+</xliff>
+`, string(data))
+
+	dicts, err := ReadXLIFFFolder("/tmp/xliff", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, testDictionaries(), dicts)
+
+	_, err = WriteXLIFFFolder("/tmp/xliff", testDictionaries(), Options{BaseLanguage: "en", Version: "3.0"})
+	assert.Error(t, err)
+}
+
+func TestReadInlineElements(t *testing.T) {
+	defer os.RemoveAll("/tmp/xliff")
+	require.NoError(t, os.MkdirAll("/tmp/xliff", os.ModePerm))
+
+	require.NoError(t, ioutil.WriteFile("/tmp/xliff/de.xlf", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">
+	<file original="strings.xml" source-language="en" target-language="de" datatype="plaintext">
+		<body>
+			<trans-unit id="title">
+				<source>&lt;b&gt;Title&lt;/b&gt;</source>
+				<target><bpt id="1">&lt;b&gt;</bpt>Titel<ept id="1">&lt;/b&gt;</ept></target>
+			</trans-unit>
+			<trans-unit id="greeting">
+				<source>Hello, %1$s!</source>
+				<target><mrk mtype="x-reviewed">Hallo</mrk>, <ph id="1">%1$s</ph>!</target>
+			</trans-unit>
+		</body>
+	</file>
+</xliff>`), os.ModePerm))
+	dicts, err := ReadXLIFFFolder("/tmp/xliff", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"title", "<b>Titel</b>",
+		"greeting", "Hallo, %1$s!",
+	), dicts["de"])
+
+	require.NoError(t, ioutil.WriteFile("/tmp/xliff/de.xlf", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:1.2" version="1.2">
+	<file original="strings.xml" source-language="en" target-language="de" datatype="plaintext">
+		<body>
+			<trans-unit id="hello">
+				<source>Hello World!</source>
+				<target>Hallo <g id="1">Welt</g>!</target>
+			</trans-unit>
+		</body>
+	</file>
+</xliff>`), os.ModePerm))
+	_, err = ReadXLIFFFolder("/tmp/xliff", Options{BaseLanguage: "en"})
+	assert.EqualError(t, err, "/tmp/xliff/de.xlf: unit hello: unsupported inline element <g>")
+
+	require.NoError(t, ioutil.WriteFile("/tmp/xliff/de.xlf", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en" trgLang="de">
+	<file id="f1">
+		<unit id="u1" name="hello">
+			<segment>
+				<source>Hello <ph id="1" dataRef="d1"/></source>
+				<target>Hallo <ph id="1" dataRef="d1"/></target>
+			</segment>
+		</unit>
+	</file>
+</xliff>`), os.ModePerm))
+	_, err = ReadXLIFFFolder("/tmp/xliff", Options{BaseLanguage: "en"})
+	assert.EqualError(t, err, "/tmp/xliff/de.xlf: unit hello: unsupported inline element <ph>")
+}
+
+func TestReadMissingTargetLanguage(t *testing.T) {
+	defer os.RemoveAll("/tmp/xliff")
+	require.NoError(t, os.MkdirAll("/tmp/xliff", os.ModePerm))
+	require.NoError(t, ioutil.WriteFile("/tmp/xliff/de.xlf", []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<xliff xmlns="urn:oasis:names:tc:xliff:document:2.0" version="2.0" srcLang="en">
+	<file id="f1">
+		<unit id="u1" name="title">
+			<segment>
+				<source>Title</source>
+				<target>Titel</target>
+			</segment>
+		</unit>
+	</file>
+</xliff>`), os.ModePerm))
+	_, err := ReadXLIFFFolder("/tmp/xliff", Options{BaseLanguage: "en"})
+	assert.EqualError(t, err, "/tmp/xliff/de.xlf: missing target language of translations")
+}
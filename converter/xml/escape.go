@@ -0,0 +1,178 @@
+package xml
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// entityRe matches xml entity and character references, e.g. "&amp;" or "&#8230;"
+var entityRe = regexp.MustCompile(`^&(lt|gt|amp|quot|apos|#[0-9]+|#x[0-9a-fA-F]+);`)
+
+// unescapedEntities defines xml entities, that are shown to translators as plain characters,
+// "&lt;" is kept as is, so it is not confused with the start of markup tag
+var unescapedEntities = map[string]string{
+	"&amp;":  "&",
+	"&quot;": `"`,
+	"&apos;": "'",
+	"&gt;":   ">",
+}
+
+// markupEnd returns the length of markup at the start of the given string, e.g. "<b>",
+// "</b>", "<!-- comment -->" or "<![CDATA[text]]>", or zero if it doesn't start with markup
+func markupEnd(s string) int {
+	if len(s) < 2 || s[0] != '<' {
+		return 0
+	}
+	var end string
+	switch {
+	case strings.HasPrefix(s, "<![CDATA["):
+		end = "]]>"
+	case strings.HasPrefix(s, "<!--"):
+		end = "-->"
+	case s[1] == '/' || s[1] == '?' || s[1] == '_' || s[1] == ':' ||
+		s[1] >= 'a' && s[1] <= 'z' || s[1] >= 'A' && s[1] <= 'Z':
+		end = ">"
+	default:
+		return 0
+	}
+	i := strings.Index(s, end)
+	if i < 0 {
+		return 0
+	}
+	return i + len(end)
+}
+
+// isSpace reports whether the given character is a whitespace collapsed by android
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+// Unescape converts the value of android string resource to the plain text, that is shown
+// to translators: escape sequences like \' \" \n \t \@ \? \\ and \uXXXX are replaced with
+// characters, double quotes around the text are removed, whitespaces outside of them are
+// collapsed, xml entities except of "&lt;" are replaced with characters, markup is kept as is
+func Unescape(value string) string {
+	var b strings.Builder
+	quoted := false
+	space := false // whether there is a pending collapsed whitespace
+
+	flushSpace := func() {
+		if space && b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		space = false
+	}
+
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+
+		if n := markupEnd(value[i:]); n > 0 {
+			flushSpace()
+			b.WriteString(value[i : i+n])
+			i += n - 1
+			continue
+		}
+
+		if c == '&' {
+			if entity := entityRe.FindString(value[i:]); entity != "" {
+				flushSpace()
+				if ch, ok := unescapedEntities[entity]; ok {
+					b.WriteString(ch)
+				} else {
+					b.WriteString(entity)
+				}
+				i += len(entity) - 1
+				continue
+			}
+		}
+
+		switch {
+		case c == '\\' && i+1 < len(value):
+			flushSpace()
+			i++
+			switch value[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if i+5 > len(value) {
+					b.WriteByte('u')
+					break
+				}
+				code, err := strconv.ParseUint(value[i+1:i+5], 16, 32)
+				if err != nil {
+					b.WriteByte('u')
+					break
+				}
+				b.WriteRune(rune(code))
+				i += 4
+			default:
+				b.WriteByte(value[i])
+			}
+		case c == '"':
+			quoted = !quoted
+		case isSpace(c) && !quoted:
+			space = true
+		default:
+			flushSpace()
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// Escape converts the plain text from translators to the value of android string resource:
+// backslashes, quotes, apostrophes, new lines, tabs and leading @ and ? are escaped, ampersands,
+// except of ones in "&lt;" and character references, and less-than signs, that don't start markup, are replaced with
+// xml entities, the text with leading, trailing or repeated whitespaces is enclosed in double
+// quotes, markup is kept as is
+func Escape(text string) string {
+	var b strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+
+		if n := markupEnd(text[i:]); n > 0 {
+			b.WriteString(text[i : i+n])
+			i += n - 1
+			continue
+		}
+
+		switch c {
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '\'':
+			b.WriteString(`\'`)
+		case '"':
+			b.WriteString(`\"`)
+		case '@', '?':
+			if b.Len() == 0 {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(c)
+		case '&':
+			// only entities, that are kept as is by Unescape, are written back as is
+			if entity := entityRe.FindString(text[i:]); entity != "" && unescapedEntities[entity] == "" {
+				b.WriteByte(c)
+				break
+			}
+			b.WriteString("&amp;")
+		case '<':
+			b.WriteString("&lt;")
+		default:
+			b.WriteByte(c)
+		}
+	}
+
+	res := b.String()
+	if strings.HasPrefix(res, " ") || strings.HasSuffix(res, " ") || strings.Contains(res, "  ") {
+		return `"` + res + `"`
+	}
+	return res
+}
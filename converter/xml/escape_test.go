@@ -0,0 +1,66 @@
+package xml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnescape(t *testing.T) {
+	tbl := []struct {
+		value string
+		text  string
+	}{
+		{`Plain text`, `Plain text`},
+		{`Don\'t`, `Don't`},
+		{`Say \"hi\"`, `Say "hi"`},
+		{`First\nSecond`, "First\nSecond"},
+		{`Column\tColumn`, "Column\tColumn"},
+		{`Back\\slash`, `Back\slash`},
+		{`\@username`, `@username`},
+		{`\?attr`, `?attr`},
+		{`Ellipsis…`, "Ellipsis…"},
+		{`"Don't touch"`, `Don't touch`},
+		{`"  spaced  "`, `  spaced  `},
+		{"  collapsed \n  text  ", `collapsed text`},
+		{`Tom &amp; Jerry`, `Tom & Jerry`},
+		{`&quot;quoted&quot; &apos;single&apos; a &gt; b`, `"quoted" 'single' a > b`},
+		{`1 &lt; 2`, `1 &lt; 2`},
+		{`Hello, <b>%1$s</b>!`, `Hello, <b>%1$s</b>!`},
+		{`<xliff:g id="count" example="5">%d</xliff:g> items`, `<xliff:g id="count" example="5">%d</xliff:g> items`},
+		{`<![CDATA[<a href="x">Don't</a>]]>`, `<![CDATA[<a href="x">Don't</a>]]>`},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.text, Unescape(tt.value), tt.value)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	tbl := []struct {
+		text  string
+		value string
+	}{
+		{`Plain text`, `Plain text`},
+		{`Don't`, `Don\'t`},
+		{`Say "hi"`, `Say \"hi\"`},
+		{"First\nSecond", `First\nSecond`},
+		{"Column\tColumn", `Column\tColumn`},
+		{`Back\slash`, `Back\\slash`},
+		{`@username`, `\@username`},
+		{`?attr`, `\?attr`},
+		{`e-mail: a@b.c?`, `e-mail: a@b.c?`},
+		{`  spaced  `, `"  spaced  "`},
+		{`Tom & Jerry`, `Tom &amp; Jerry`},
+		{`Tom &amp; Jerry`, `Tom &amp;amp; Jerry`},
+		{`1 &lt; 2 &#8230;`, `1 &lt; 2 &#8230;`},
+		{`Hello, <b>%1$s</b>!`, `Hello, <b>%1$s</b>!`},
+		{`<xliff:g id="count">%d</xliff:g> items`, `<xliff:g id="count">%d</xliff:g> items`},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.value, Escape(tt.text), tt.text)
+		assert.Equal(t, tt.text, Unescape(Escape(tt.text)), tt.text)
+	}
+
+	// less-than sign, that doesn't start markup, is shown to translators as "&lt;"
+	assert.Equal(t, `1 &lt; 2 > 0`, Escape(`1 < 2 > 0`))
+}
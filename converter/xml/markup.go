@@ -0,0 +1,102 @@
+package xml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"io"
+	"sort"
+	"strings"
+)
+
+// markupTags parses the inline markup of the given value of android string resource, e.g.
+// "<b>", "<xliff:g>" or "<annotation>", and returns names of its tags in alphabetical order,
+// CDATA sections are treated as text, err is not nil if the markup is not well-formed
+func markupTags(value string) (tags []string, err error) {
+	decoder := xml.NewDecoder(strings.NewReader("<string>" + value + "</string>"))
+	tags = []string{}
+	root := true // whether the next start element is the wrapping <string> tag
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root {
+			root = false
+			continue
+		}
+		name := start.Name.Local
+		if start.Name.Space != "" {
+			name = start.Name.Space + ":" + name
+		}
+		tags = append(tags, name)
+	}
+	sort.Strings(tags)
+	return tags, nil
+}
+
+// formatTags returns the human-readable list of the given tags, e.g. "<b>, <i>"
+func formatTags(tags []string) string {
+	if len(tags) == 0 {
+		return "no tags"
+	}
+	res := make([]string, len(tags))
+	for i, tag := range tags {
+		res[i] = "<" + tag + ">"
+	}
+	return strings.Join(res, ", ")
+}
+
+// ValidateMarkup checks, that inline markup of all strings in the given set of dictionaries is
+// well-formed and translations have the same tags as strings of the base language, values are
+// checked as they are written to xml files, i.e. escaped unless opts.Raw is set, the returned
+// error lists all offending strings
+func ValidateMarkup(dicts general.Dictionaries, opts Options) error {
+	prepare := func(value string) string {
+		if opts.Raw {
+			return value
+		}
+		return Escape(value)
+	}
+
+	baseTags := map[string][]string{}
+	var issues []string
+
+	for _, langCode := range general.SortedLanguages(dicts, opts.BaseLanguage) {
+		if general.IsMeta(langCode) {
+			continue
+		}
+		d := dicts[langCode]
+		for _, code := range d.Codes() {
+			tags, err := markupTags(prepare(d.Get(code)))
+			if err != nil {
+				issues = append(issues, fmt.Sprintf("%s: %s: malformed markup: %v", langCode, code, err))
+				continue
+			}
+			if langCode == opts.BaseLanguage {
+				baseTags[code] = tags
+				continue
+			}
+			base, ok := baseTags[code]
+			if !ok {
+				continue
+			}
+			if strings.Join(tags, " ") != strings.Join(base, " ") {
+				issues = append(issues, fmt.Sprintf("%s: %s: has %s, but source string has %s",
+					langCode, code, formatTags(tags), formatTags(base)))
+			}
+		}
+	}
+
+	if len(issues) > 0 {
+		return fmt.Errorf("invalid markup in %d string(s):\n\t%s", len(issues), strings.Join(issues, "\n\t"))
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package xml
+
+import (
+	"os"
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkupTags(t *testing.T) {
+	tbl := []struct {
+		value string
+		tags  []string
+		err   bool
+	}{
+		{`Plain text`, []string{}, false},
+		{`<i>Hello</i>, <b>%1$s</b>!`, []string{"b", "i"}, false},
+		{`<xliff:g id="count" example="5">%d</xliff:g> items`, []string{"xliff:g"}, false},
+		{`<annotation font="title">Title</annotation>`, []string{"annotation"}, false},
+		{`<![CDATA[<a href="x">link</a>]]>`, []string{}, false},
+		{`<b>Hello`, nil, true},
+		{`<b>Hello</i>`, nil, true},
+		{`Hello</b>`, nil, true},
+	}
+	for _, tt := range tbl {
+		tags, err := markupTags(tt.value)
+		if tt.err {
+			assert.Error(t, err, tt.value)
+			continue
+		}
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.tags, tags, tt.value)
+	}
+}
+
+func TestValidateMarkup(t *testing.T) {
+	dicts := general.Dictionaries{
+		"en": general.DictionaryOf(
+			"greeting", "Hello, <b>%1$s</b>!",
+			"count", `<xliff:g id="count">%d</xliff:g> items`,
+			"compare", "1 < 2",
+		),
+		"de": general.DictionaryOf(
+			"greeting", "Hallo, <b>%1$s</b>!",
+			"count", `<xliff:g id="count">%d</xliff:g> Elemente`,
+			"compare", "1 < 2",
+		),
+	}
+	assert.NoError(t, ValidateMarkup(dicts, Options{BaseLanguage: "en"}))
+
+	dicts["de"].Set("greeting", "Hallo, <b>%1$s!")
+	dicts["de"].Set("count", "%d Elemente")
+	err := ValidateMarkup(dicts, Options{BaseLanguage: "en"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "de: greeting: malformed markup")
+	assert.Contains(t, err.Error(), "de: count: has no tags, but source string has <xliff:g>")
+
+	defer os.RemoveAll("/tmp/res")
+	_, err = WriteResFolder("/tmp/res", dicts, Options{BaseLanguage: "en"})
+	require.Error(t, err)
+	_, err = os.Stat("/tmp/res")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,104 @@
+package xml
+
+import (
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"io/ioutil"
+	"os"
+)
+
+// mergeXMLFile merges the given ResourcesEntry into the xml file at the given path, only values
+// of changed resources are rewritten and missing resources are added, if there is no such file,
+// it is created, the returned file is nil if the existing file wasn't changed
+func mergeXMLFile(path string, r ResourcesEntry) (file *os.File, err error) {
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		return r.WriteToXMLFile(path)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, changed, err := rewriteXML(data, r)
+	if err != nil || !changed {
+		return nil, err
+	}
+
+	return general.CreateFile(path, data)
+}
+
+// withExistingAttrs returns copies of the given dictionaries of "translatable" attributes and
+// file names with attributes of strings from the existing values folder at the given path,
+// file names from the given dictionary take precedence over existing ones
+func withExistingAttrs(attrs *general.Dictionary, files *general.Dictionary, path string) (
+	resAttrs *general.Dictionary, resFiles *general.Dictionary, err error) {
+	resAttrs, resFiles = general.NewDictionary(), general.NewDictionary()
+	for _, name := range attrs.Codes() {
+		resAttrs.Set(name, attrs.Get(name))
+	}
+	for _, code := range files.Codes() {
+		resFiles.Set(code, files.Get(code))
+	}
+
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		return resAttrs, resFiles, nil
+	}
+
+	existing, err := readValuesFolder(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, name := range existing.untranslatable {
+		resAttrs.Set(name, "false")
+	}
+	for _, code := range existing.files.Codes() {
+		if _, ok := resFiles.Lookup(code); !ok {
+			resFiles.Set(code, existing.files.Get(code))
+		}
+	}
+	return resAttrs, resFiles, nil
+}
+
+// existingStrings returns strings of all xml files in the values folder at the given path,
+// the dictionary is nil if there is no such folder
+func existingStrings(path string) (d *general.Dictionary, err error) {
+	if _, err = os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	folder, err := readValuesFolder(path)
+	if err != nil {
+		return nil, err
+	}
+	return folder.dict, nil
+}
+
+// existingValuesFolders returns names of existing values folders with locale qualifiers in the "res"
+// folder at the given path by their normalized names, e.g. "values-pt-rBR" -> "values-b+pt+BR",
+// so translations are merged into the folders as they are named in the project
+func existingValuesFolders(path string) (folders map[string]string, err error) {
+	contents, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	folders = map[string]string{}
+	for _, entry := range contents {
+		if !entry.IsDir() {
+			continue
+		}
+		if l, base, ok := ParseValuesFolder(entry.Name()); ok && !base {
+			folders[ValuesPrefix+l.Qualifier()] = entry.Name()
+		}
+	}
+	return folders, nil
+}
+
+// mkdir creates the directory at the given path, the directory
+// may already exist in merge mode
+func mkdir(path string, merge bool) error {
+	if merge {
+		return os.MkdirAll(path, ExportFileMode)
+	}
+	return os.Mkdir(path, ExportFileMode)
+}
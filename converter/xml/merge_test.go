@@ -0,0 +1,120 @@
+package xml
+
+import (
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeResFolder(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	require.NoError(t, os.MkdirAll("/tmp/res/values-tl", ExportFileMode))
+	require.NoError(t, os.MkdirAll("/tmp/res/drawable", ExportFileMode))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values-tl/colors.xml", []byte(`<resources/>`), ExportFileMode))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values-tl/strings.xml", []byte(`<?xml version="1.0" encoding="utf-8"?>
+<resources>
+	<string name="old_str">Old translation</string>
+	<color name="accent">#FF0000</color>
+	<string name="test_str">Outdated translation</string>
+</resources>`), ExportFileMode))
+
+	_, err := WriteResFolder("/tmp/res", general.Dictionaries{
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+			"new_str", "New string",
+		),
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+			"new_str", "New translation",
+		),
+	}, Options{BaseLanguage: "en", Merge: true})
+	require.NoError(t, err)
+	assert.FileExists(t, "/tmp/res/values/strings.xml")
+	assert.DirExists(t, "/tmp/res/drawable")
+
+	colors, err := ioutil.ReadFile("/tmp/res/values-tl/colors.xml")
+	require.NoError(t, err)
+	assert.Equal(t, `<resources/>`, string(colors))
+
+	readed, err := ReadXMLFile("/tmp/res/values-tl/strings.xml")
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"old_str", "Old translation",
+		"test_str", "Test translation",
+		"new_str", "New translation",
+	), readed.ConvertToDictionary())
+	assert.Equal(t, []RawEntry{
+		RawEntry{
+			XMLName: xml.Name{Local: "color"},
+			Attrs:   []xml.Attr{{Name: xml.Name{Local: "name"}, Value: "accent"}},
+			Value:   "#FF0000",
+		},
+	}, readed.Others)
+}
+
+func TestMergeExistingLocaleFolder(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	require.NoError(t, os.MkdirAll("/tmp/res/values-b+pt+BR", ExportFileMode))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values-b+pt+BR/strings.xml",
+		[]byte(`<resources><string name="title">Título</string></resources>`), ExportFileMode))
+
+	_, err := WriteResFolder("/tmp/res", general.Dictionaries{
+		"en": general.DictionaryOf(
+			"title", "Title",
+			"new_str", "New string",
+		),
+		"pt-rBR": general.DictionaryOf(
+			"title", "Título",
+			"new_str", "Nova string",
+		),
+	}, Options{BaseLanguage: "en", Merge: true})
+	require.NoError(t, err)
+	_, err = os.Stat("/tmp/res/values-pt-rBR")
+	assert.True(t, os.IsNotExist(err))
+
+	readed, err := ReadXMLFile("/tmp/res/values-b+pt+BR/strings.xml")
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"title", "Título",
+		"new_str", "Nova string",
+	), readed.ConvertToDictionary())
+}
+
+func TestMergeSparseStringArray(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	require.NoError(t, os.MkdirAll("/tmp/res/values-de", ExportFileMode))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values-de/strings.xml", []byte(`<resources>
+	<string-array name="planets">
+		<item>Merkur</item>
+		<item>Venus</item>
+		<item>Erde alt</item>
+	</string-array>
+</resources>`), ExportFileMode))
+
+	_, err := WriteResFolder("/tmp/res", general.Dictionaries{
+		"en": general.DictionaryOf(
+			"planets[0]", "Mercury",
+			"planets[1]", "Venus",
+			"planets[2]", "Earth",
+		),
+		"de": general.DictionaryOf(
+			"planets[2]", "Erde",
+		),
+	}, Options{BaseLanguage: "en", Merge: true})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile("/tmp/res/values-de/strings.xml")
+	require.NoError(t, err)
+	assert.Equal(t, `<resources>
+	<string-array name="planets">
+		<item>Merkur</item>
+		<item>Venus</item>
+		<item>Erde</item>
+	</string-array>
+</resources>`, string(data))
+}
@@ -0,0 +1,199 @@
+package xml
+
+import (
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// xliffSpanRe matches <xliff:g></xliff:g> spans, that mark untranslatable parts of strings
+	xliffSpanRe = regexp.MustCompile(`(?s)<xliff:g\b[^>]*>(.*?)</xliff:g>`)
+	// xliffIDRe matches the id attribute of <xliff:g> tag
+	xliffIDRe = regexp.MustCompile(`^<xliff:g\b[^>]*?\sid\s*=\s*["']([^"']*)["']`)
+)
+
+// placeholder defines a single <xliff:g></xliff:g> span and the token, that replaces it
+type placeholder struct {
+	token string // token shown to translators, e.g. "{count}"
+	span  string // original markup, e.g. `<xliff:g id="count">%d</xliff:g>`
+	id    string // id attribute of the span, e.g. "count"
+	inner string // content of the span, e.g. "%d"
+}
+
+// placeholders returns tokens for all <xliff:g></xliff:g> spans of the given value, tokens
+// are made of ids of spans, spans without ids are numbered by their positions and
+// repeated ids get numeric suffixes, e.g. "{count}", "{count_2}" or "{1}"
+func placeholders(value string) (res []placeholder) {
+	used := map[string]bool{}
+	for i, m := range xliffSpanRe.FindAllStringSubmatch(value, -1) {
+		ph := placeholder{span: m[0], inner: m[1]}
+		name := strconv.Itoa(i + 1)
+		if id := xliffIDRe.FindStringSubmatch(ph.span); id != nil && id[1] != "" {
+			ph.id, name = id[1], id[1]
+		}
+		ph.token = "{" + name + "}"
+		for n := 2; used[ph.token]; n++ {
+			ph.token = "{" + name + "_" + strconv.Itoa(n) + "}"
+		}
+		used[ph.token] = true
+		res = append(res, ph)
+	}
+	return res
+}
+
+// part defines a piece of string, that is either a plain text or a span or token
+type part struct {
+	text  string
+	plain bool
+}
+
+// protect replaces <xliff:g></xliff:g> spans of the value with tokens of the matching spans of
+// the source string: spans with the same markup, then with the same id, then with the same
+// content, so spans without ids keep their tokens if translation reorders them, contents of
+// source spans, that are left without tags in the value, e.g. "%d" for
+// `<xliff:g id="count">%d</xliff:g>`, are replaced with tokens as well
+func protect(value string, source []placeholder) string {
+	phs := placeholders(value)
+	tokens := make([]string, len(phs)) // tokens of spans of the value
+	used := make([]bool, len(source))  // whether the source span has been matched
+	matchers := []func(ph placeholder, src placeholder) bool{
+		func(ph placeholder, src placeholder) bool { return ph.span == src.span },
+		func(ph placeholder, src placeholder) bool { return ph.id != "" && ph.id == src.id },
+		func(ph placeholder, src placeholder) bool { return ph.inner == src.inner },
+	}
+	for _, match := range matchers {
+		for i, ph := range phs {
+			for j, src := range source {
+				if tokens[i] == "" && !used[j] && match(ph, src) {
+					tokens[i], used[j] = src.token, true
+				}
+			}
+		}
+	}
+
+	var parts []part
+	pos := 0
+	for i, loc := range xliffSpanRe.FindAllStringIndex(value, -1) {
+		parts = append(parts, part{text: value[pos:loc[0]], plain: true})
+		if tokens[i] != "" {
+			parts = append(parts, part{text: tokens[i]})
+		} else {
+			parts = append(parts, part{text: value[loc[0]:loc[1]]})
+		}
+		pos = loc[1]
+	}
+	parts = append(parts, part{text: value[pos:], plain: true})
+
+	for j, src := range source {
+		if used[j] || src.inner == "" {
+			continue
+		}
+		for k, p := range parts {
+			n := strings.Index(p.text, src.inner)
+			if !p.plain || n < 0 {
+				continue
+			}
+			parts = append(parts[:k], append([]part{
+				{text: p.text[:n], plain: true},
+				{text: src.token},
+				{text: p.text[n+len(src.inner):], plain: true},
+			}, parts[k+1:]...)...)
+			break
+		}
+	}
+
+	var b strings.Builder
+	for _, p := range parts {
+		b.WriteString(p.text)
+	}
+	return b.String()
+}
+
+// ProtectPlaceholders returns the copy of the given set of dictionaries, where <xliff:g></xliff:g>
+// spans of strings of the base language are replaced with tokens like "{count}" in all languages,
+// spans and arguments without spans of translations are matched to spans of the base language
+// (see protect), original spans are kept in the general.PlaceholdersMeta dictionary, so they
+// can be restored by RestorePlaceholders
+func ProtectPlaceholders(dicts general.Dictionaries, baseLang string) (res general.Dictionaries) {
+	res = make(general.Dictionaries)
+	spans := general.NewDictionary()
+
+	base := dicts[baseLang]
+	for _, code := range base.Codes() {
+		if phs := placeholders(base.Get(code)); len(phs) > 0 {
+			spans.Set(code, strings.Join(spanList(phs), ""))
+		}
+	}
+
+	for langCode, d := range dicts {
+		if general.IsMeta(langCode) {
+			res[langCode] = d
+			continue
+		}
+		res[langCode] = general.NewDictionary()
+		for _, code := range d.Codes() {
+			res[langCode].Set(code, protect(d.Get(code), placeholders(spans.Get(code))))
+		}
+	}
+
+	if spans.Len() > 0 {
+		res[general.PlaceholdersMeta] = spans
+	}
+	return res
+}
+
+// spanList returns spans of the given placeholders
+func spanList(phs []placeholder) (spans []string) {
+	for _, ph := range phs {
+		spans = append(spans, ph.span)
+	}
+	return spans
+}
+
+// RestorePlaceholders returns the copy of the given set of dictionaries, where tokens made by
+// ProtectPlaceholders are replaced back with the original <xliff:g></xliff:g> spans from the
+// general.PlaceholdersMeta dictionary, the returned error lists all strings, that miss tokens,
+// items of plurals may omit tokens as they may omit arguments, e.g. "One apple" for "{count} apples"
+func RestorePlaceholders(dicts general.Dictionaries, baseLang string) (res general.Dictionaries, err error) {
+	spans, ok := dicts[general.PlaceholdersMeta]
+	if !ok {
+		return dicts, nil
+	}
+
+	res = make(general.Dictionaries)
+	var issues []string
+
+	for _, langCode := range general.SortedLanguages(dicts, baseLang) {
+		d := dicts[langCode]
+		if langCode == general.PlaceholdersMeta {
+			continue
+		}
+		if general.IsMeta(langCode) {
+			res[langCode] = d
+			continue
+		}
+		res[langCode] = general.NewDictionary()
+		for _, code := range d.Codes() {
+			value := d.Get(code)
+			_, _, plural := general.ParsePluralCode(code)
+			for _, ph := range placeholders(spans.Get(code)) {
+				if !strings.Contains(value, ph.token) {
+					if !plural {
+						issues = append(issues, fmt.Sprintf("%s: %s: missing placeholder %s", langCode, code, ph.token))
+					}
+					continue
+				}
+				value = strings.Replace(value, ph.token, ph.span, -1)
+			}
+			res[langCode].Set(code, value)
+		}
+	}
+
+	if len(issues) > 0 {
+		return nil, fmt.Errorf("missing placeholders in %d string(s):\n\t%s", len(issues), strings.Join(issues, "\n\t"))
+	}
+	return res, nil
+}
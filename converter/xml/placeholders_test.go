@@ -0,0 +1,104 @@
+package xml
+
+import (
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlaceholders(t *testing.T) {
+	assert.Equal(t, []placeholder{
+		{token: "{count}", span: `<xliff:g id="count" example="5">%1$d</xliff:g>`, id: "count", inner: "%1$d"},
+		{token: "{count_2}", span: `<xliff:g id="count">%2$d</xliff:g>`, id: "count", inner: "%2$d"},
+		{token: "{3}", span: `<xliff:g>%3$s</xliff:g>`, inner: "%3$s"},
+	}, placeholders(`<xliff:g id="count" example="5">%1$d</xliff:g> of <xliff:g id="count">%2$d</xliff:g> in <xliff:g>%3$s</xliff:g>`))
+	assert.Nil(t, placeholders("Plain text"))
+}
+
+func TestProtectRestorePlaceholders(t *testing.T) {
+	dicts := general.Dictionaries{
+		"en": general.DictionaryOf(
+			"greeting", `Hello, <xliff:g id="name" example="Bob">%1$s</xliff:g>!`,
+			"apples#one", `One apple`,
+			"apples#other", `<xliff:g id="count">%d</xliff:g> apples`,
+			"plain", "Plain text",
+		),
+		"de": general.DictionaryOf(
+			"greeting", `Hallo, <xliff:g id="name" example="Hans">%1$s</xliff:g>!`,
+			"apples#one", `Ein Apfel`,
+			"apples#other", `<xliff:g id="count">%d</xliff:g> Äpfel`,
+			"plain", "Einfacher Text",
+		),
+	}
+
+	protected := ProtectPlaceholders(dicts, "en")
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"greeting", `Hello, {name}!`,
+			"apples#one", `One apple`,
+			"apples#other", `{count} apples`,
+			"plain", "Plain text",
+		),
+		"de": general.DictionaryOf(
+			"greeting", `Hallo, {name}!`,
+			"apples#one", `Ein Apfel`,
+			"apples#other", `{count} Äpfel`,
+			"plain", "Einfacher Text",
+		),
+		general.PlaceholdersMeta: general.DictionaryOf(
+			"greeting", `<xliff:g id="name" example="Bob">%1$s</xliff:g>`,
+			"apples#other", `<xliff:g id="count">%d</xliff:g>`,
+		),
+	}, protected)
+
+	restored, err := RestorePlaceholders(protected, "en")
+	require.NoError(t, err)
+	dicts["de"].Set("greeting", `Hallo, <xliff:g id="name" example="Bob">%1$s</xliff:g>!`)
+	assert.Equal(t, dicts, restored)
+
+	protected["de"].Set("greeting", "Hallo!")
+	protected["de"].Set("apples#one", "Ein Apfel")
+	_, err = RestorePlaceholders(protected, "en")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "de: greeting: missing placeholder {name}")
+	assert.NotContains(t, err.Error(), "apples")
+}
+
+func TestProtectPlaceholdersOfTranslations(t *testing.T) {
+	source := placeholders(`<xliff:g>%1$s</xliff:g> by <xliff:g>%2$s</xliff:g>, <xliff:g id="count">%3$d</xliff:g> items`)
+	tbl := []struct {
+		value     string
+		protected string
+	}{
+		{`Nach <xliff:g>%2$s</xliff:g> von <xliff:g>%1$s</xliff:g>, <xliff:g id="count">%3$d</xliff:g> Elemente`,
+			`Nach {2} von {1}, {count} Elemente`},
+		{`Von %2$s: %1$s, %3$d Elemente`, `Von {2}: {1}, {count} Elemente`},
+		{`<xliff:g id="count" example="5">%3$d</xliff:g> Elemente: %1$s, %2$s`, `{count} Elemente: {1}, {2}`},
+		{`<xliff:g id="other">%4$s</xliff:g> %1$s`, `<xliff:g id="other">%4$s</xliff:g> {1}`},
+	}
+	for _, tt := range tbl {
+		assert.Equal(t, tt.protected, protect(tt.value, source), tt.value)
+	}
+}
+
+func TestRestorePlaceholdersWithoutSpans(t *testing.T) {
+	dicts := general.Dictionaries{
+		"en": general.DictionaryOf(
+			"items", `<xliff:g id="count">%d</xliff:g> items`,
+			"by", `<xliff:g>%1$s</xliff:g> by <xliff:g>%2$s</xliff:g>`,
+		),
+		"de": general.DictionaryOf(
+			"items", `%d Elemente`,
+			"by", `Nach <xliff:g>%2$s</xliff:g> von <xliff:g>%1$s</xliff:g>`,
+		),
+	}
+
+	restored, err := RestorePlaceholders(ProtectPlaceholders(dicts, "en"), "en")
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"items", `<xliff:g id="count">%d</xliff:g> Elemente`,
+		"by", `Nach <xliff:g>%2$s</xliff:g> von <xliff:g>%1$s</xliff:g>`,
+	), restored["de"])
+}
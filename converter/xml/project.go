@@ -0,0 +1,138 @@
+package xml
+
+import (
+	"fmt"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// ResFolder defines the name of folder with resources of android module
+	ResFolder = "res"
+	// SourcesFolder defines the name of folder with source sets of android module
+	SourcesFolder = "src"
+)
+
+// skippedFolders defines folders of android project, that never contain sources
+var skippedFolders = map[string]bool{
+	"build":        true,
+	"node_modules": true,
+}
+
+// FindResFolders returns paths of all "res" folders of modules and source sets of android project
+// at the given path, e.g. "app/src/main/res" or "feature/src/free/res", paths are relative to the
+// root of project and always use forward slashes
+func FindResFolders(path string) (modules []string, err error) {
+	err = filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		if p != path && (skippedFolders[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+			return filepath.SkipDir
+		}
+
+		// looking for <module>/src/<source set>/res
+		if info.Name() != ResFolder || filepath.Base(filepath.Dir(filepath.Dir(p))) != SourcesFolder {
+			return nil
+		}
+
+		rel, err := filepath.Rel(path, p)
+		if err != nil {
+			return err
+		}
+		modules = append(modules, filepath.ToSlash(rel))
+		return filepath.SkipDir
+	})
+	return modules, err
+}
+
+// ReadProject reads "res" folders of all modules and source sets of android project at the given path,
+// codes of strings are prefixed with paths of "res" folders (see general.ModuleCode), that are also
+// listed in the general.ModuleMeta dictionary
+func ReadProject(path string, opts Options) (dicts general.Dictionaries, err error) {
+	modules, err := FindResFolders(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dicts = make(general.Dictionaries)
+
+	for _, module := range modules {
+		var moduleDicts general.Dictionaries
+		moduleDicts, err = ReadResFolder(filepath.Join(path, filepath.FromSlash(module)), opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", module, err)
+		}
+
+		for _, langCode := range general.SortedLanguages(moduleDicts, opts.BaseLanguage) {
+			if dicts[langCode] == nil {
+				dicts[langCode] = general.NewDictionary()
+			}
+			d := moduleDicts[langCode]
+			for _, code := range d.Codes() {
+				moduleCode := general.ModuleCode(module, code)
+				dicts[langCode].Set(moduleCode, d.Get(code))
+
+				if dicts[general.ModuleMeta] == nil {
+					dicts[general.ModuleMeta] = general.NewDictionary()
+				}
+				dicts[general.ModuleMeta].Set(moduleCode, module)
+			}
+		}
+	}
+
+	return dicts, nil
+}
+
+// WriteProject writes the given set of dictionaries to "res" folders of android project at the given
+// path, each string is written to the "res" folder from its code (see general.ModuleCode), "res"
+// folders are always updated in merge mode
+func WriteProject(path string, dicts general.Dictionaries, opts Options) (files []*os.File, err error) {
+	// validating all modules at once, so none of them is written if any string is invalid
+	if err = ValidateMarkup(dicts, opts); err != nil {
+		return nil, err
+	}
+
+	modules := []string{}
+	moduleDicts := map[string]general.Dictionaries{}
+
+	for _, langCode := range general.SortedLanguages(dicts, opts.BaseLanguage) {
+		if langCode == general.ModuleMeta {
+			continue
+		}
+		d := dicts[langCode]
+		for _, moduleCode := range d.Codes() {
+			module, code, ok := general.ParseModuleCode(moduleCode)
+			if !ok {
+				return nil, fmt.Errorf("string %s doesn't define its module", moduleCode)
+			}
+			if moduleDicts[module] == nil {
+				modules = append(modules, module)
+				moduleDicts[module] = make(general.Dictionaries)
+			}
+			if moduleDicts[module][langCode] == nil {
+				moduleDicts[module][langCode] = general.NewDictionary()
+			}
+			moduleDicts[module][langCode].Set(code, d.Get(moduleCode))
+		}
+	}
+
+	opts.Merge = true
+	files = []*os.File{}
+
+	for _, module := range modules {
+		var moduleFiles []*os.File
+		moduleFiles, err = WriteResFolder(filepath.Join(path, filepath.FromSlash(module)), moduleDicts[module], opts)
+		files = append(files, moduleFiles...)
+		if err != nil {
+			return files, fmt.Errorf("%s: %v", module, err)
+		}
+	}
+
+	return files, nil
+}
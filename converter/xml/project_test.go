@@ -0,0 +1,63 @@
+package xml
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadWriteProject(t *testing.T) {
+	defer os.RemoveAll("/tmp/project")
+	for _, dir := range []string{
+		"/tmp/project/app/src/main/res/values",
+		"/tmp/project/app/src/free/res/values",
+		"/tmp/project/app/build/intermediates/src/main/res/values",
+		"/tmp/project/lib/src/main/res/values",
+	} {
+		require.NoError(t, os.MkdirAll(dir, ExportFileMode))
+		require.NoError(t, ioutil.WriteFile(dir+"/strings.xml", []byte(`<resources>
+	<string name="title">Title</string>
+</resources>`), ExportFileMode))
+	}
+
+	modules, err := FindResFolders("/tmp/project")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app/src/free/res", "app/src/main/res", "lib/src/main/res"}, modules)
+
+	dicts, err := ReadProject("/tmp/project", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"app/src/free/res:title", "Title",
+			"app/src/main/res:title", "Title",
+			"lib/src/main/res:title", "Title",
+		),
+		general.ModuleMeta: general.DictionaryOf(
+			"app/src/free/res:title", "app/src/free/res",
+			"app/src/main/res:title", "app/src/main/res",
+			"lib/src/main/res:title", "lib/src/main/res",
+		),
+	}, dicts)
+
+	dicts["tl"] = general.DictionaryOf(
+		"app/src/main/res:title", "App translation",
+		"lib/src/main/res:title", "Lib translation",
+	)
+	_, err = WriteProject("/tmp/project", dicts, Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+
+	readed, err := ReadXMLFile("/tmp/project/lib/src/main/res/values-tl/strings.xml")
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf("title", "Lib translation"), readed.ConvertToDictionary())
+	_, err = os.Stat("/tmp/project/app/src/free/res/values-tl/strings.xml")
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = WriteProject("/tmp/project", general.Dictionaries{
+		"en": general.DictionaryOf("title", "Title"),
+	}, Options{BaseLanguage: "en"})
+	assert.EqualError(t, err, "string title doesn't define its module")
+}
@@ -0,0 +1,20 @@
+package xml
+
+import (
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"strings"
+)
+
+// ParseValuesFolder parses qualifiers of the given name of values folder, e.g. "values-pt-rBR",
+// base is true for the default "values" folder, ok is false if the folder is not a values folder
+// or it has qualifiers other than locale, e.g. "values-night", "values-v21" or "values-de-land"
+func ParseValuesFolder(name string) (l general.Locale, base bool, ok bool) {
+	if name == ValuesFolder {
+		return l, true, true
+	}
+	if !strings.HasPrefix(name, ValuesPrefix) {
+		return l, false, false
+	}
+	l, ok = general.ParseLocaleQualifier(name[len(ValuesPrefix):])
+	return l, false, ok
+}
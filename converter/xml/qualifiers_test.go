@@ -0,0 +1,53 @@
+package xml
+
+import (
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseValuesFolder(t *testing.T) {
+	tbl := []struct {
+		folder    string
+		locale    general.Locale
+		base      bool
+		ok        bool
+		qualifier string
+	}{
+		{"values", general.Locale{}, true, true, ""},
+		{"values-de", general.Locale{Language: "de"}, false, true, "de"},
+		{"values-pt-rBR", general.Locale{Language: "pt", Region: "BR"}, false, true, "pt-rBR"},
+		{"values-b+sr+Latn", general.Locale{Language: "sr", Script: "Latn"}, false, true, "b+sr+Latn"},
+		{"values-b+zh+Hant+TW", general.Locale{Language: "zh", Script: "Hant", Region: "TW"}, false, true, "b+zh+Hant+TW"},
+		{"values-b+es+419", general.Locale{Language: "es", Region: "419"}, false, true, "b+es+419"},
+		{"values-b+pt+BR", general.Locale{Language: "pt", Region: "BR"}, false, true, "pt-rBR"},
+		{"values-fil", general.Locale{Language: "fil"}, false, true, "fil"},
+		{"values-night", general.Locale{}, false, false, ""},
+		{"values-v21", general.Locale{}, false, false, ""},
+		{"values-land", general.Locale{}, false, false, ""},
+		{"values-sw600dp", general.Locale{}, false, false, ""},
+		{"values-car", general.Locale{}, false, false, ""},
+		{"values-de-land", general.Locale{}, false, false, ""},
+		{"values-mcc310-en", general.Locale{}, false, false, ""},
+		{"values-b+sr+Latn+x+y", general.Locale{}, false, false, ""},
+		{"drawable-de", general.Locale{}, false, false, ""},
+	}
+	for _, tt := range tbl {
+		locale, base, ok := ParseValuesFolder(tt.folder)
+		assert.Equal(t, tt.ok, ok, tt.folder)
+		assert.Equal(t, tt.base, base, tt.folder)
+		if !ok || base {
+			continue
+		}
+		assert.Equal(t, tt.locale, locale, tt.folder)
+		assert.Equal(t, tt.qualifier, locale.Qualifier(), tt.folder)
+	}
+}
+
+func TestValuesFolderName(t *testing.T) {
+	assert.Equal(t, "values", valuesFolderName("en", "en"))
+	assert.Equal(t, "values-pt-rBR", valuesFolderName("pt-rBR", "en"))
+	assert.Equal(t, "values-pt-rBR", valuesFolderName("b+pt+BR", "en"))
+	assert.Equal(t, "values-b+sr+Latn", valuesFolderName("b+sr+Latn", "en"))
+}
@@ -0,0 +1,326 @@
+package xml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// span defines a part of xml document by byte offsets of its start and end
+type span struct {
+	start int64
+	end   int64
+}
+
+// element defines positions of a single resource or its item in xml document
+type element struct {
+	tag    string     // name of xml tag
+	key    string     // name of resource, quantity or index of item
+	inner  span       // content between start and end tags
+	indent string     // whitespaces before the element on its line
+	items  []*element // items of plurals and string-array resources
+}
+
+// selfClosing reports whether the element is written as <tag/>
+func (e *element) selfClosing(data []byte) bool {
+	return e.inner.start == e.inner.end && bytes.HasSuffix(data[:e.inner.start], []byte("/>"))
+}
+
+// item returns the item of element with the given key or nil if there is no such item
+func (e *element) item(key string) *element {
+	for _, item := range e.items {
+		if item.key == key {
+			return item
+		}
+	}
+	return nil
+}
+
+// document defines positions of resources in xml document, that are enough
+// to rewrite their values without touching anything else
+type document struct {
+	data      []byte
+	root      *element            // <resources></resources> tag
+	resources map[string]*element // resources by their tags and names
+	unit      string              // indentation of resources inside of <resources></resources> tag
+	xliff     bool                // whether the root tag declares the xliff namespace
+}
+
+// resourceKey returns the key of resource with the given tag and name in document
+func resourceKey(tag string, name string) string {
+	return tag + ":" + name
+}
+
+// lineIndent returns whitespaces between the beginning of the line and the given offset,
+// or an empty string if there is something else before the offset on the same line
+func lineIndent(data []byte, offset int64) string {
+	i := offset
+	for i > 0 && (data[i-1] == ' ' || data[i-1] == '\t') {
+		i--
+	}
+	if i > 0 && data[i-1] != '\n' {
+		return ""
+	}
+	return string(data[i:offset])
+}
+
+// attrValue returns the value of attribute with the given name or an empty string
+func attrValue(attrs []xml.Attr, name string) string {
+	for _, attr := range attrs {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
+
+// scanDocument finds positions of all string, plurals and string-array resources in the given xml document
+func scanDocument(data []byte) (doc *document, err error) {
+	doc = &document{data: data, resources: map[string]*element{}}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+
+	var stack []*element
+	for {
+		offset := decoder.InputOffset()
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			e := &element{
+				tag:    t.Name.Local,
+				inner:  span{start: decoder.InputOffset()},
+				indent: lineIndent(data, offset),
+			}
+			switch len(stack) {
+			case 0:
+				doc.root = e
+				for _, attr := range t.Attr {
+					if attr.Name.Space == "xmlns" && attr.Name.Local == "xliff" {
+						doc.xliff = true
+					}
+				}
+			case 1:
+				if doc.unit == "" {
+					doc.unit = e.indent
+				}
+				switch e.tag {
+				case "string", "plurals", "string-array":
+					e.key = attrValue(t.Attr, "name")
+					doc.resources[resourceKey(e.tag, e.key)] = e
+				}
+			case 2:
+				parent := stack[1]
+				switch {
+				case e.tag == "item" && parent.tag == "plurals":
+					e.key = attrValue(t.Attr, "quantity")
+					parent.items = append(parent.items, e)
+				case e.tag == "item" && parent.tag == "string-array":
+					e.key = strconv.Itoa(len(parent.items))
+					parent.items = append(parent.items, e)
+				}
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			e := stack[len(stack)-1]
+			e.inner.end = offset
+			stack = stack[:len(stack)-1]
+		}
+	}
+
+	if doc.root == nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return doc, nil
+}
+
+// edit defines a replacement of the given span of xml document by the given text
+type edit struct {
+	span
+	text string
+}
+
+// rewriter collects edits of xml document
+type rewriter struct {
+	doc   *document
+	edits []edit
+}
+
+// setValue replaces the content of the given element with the given value if it differs,
+// values, that differ only in escaping, e.g. "Don\'t" and "\"Don't\"", are treated as equal
+func (w *rewriter) setValue(e *element, value string) {
+	data := w.doc.data
+	if e.selfClosing(data) {
+		if value == "" {
+			return
+		}
+		// <tag attrs/> -> <tag attrs>value</tag>
+		w.edits = append(w.edits, edit{
+			span: span{start: e.inner.start - 2, end: e.inner.start},
+			text: ">" + value + "</" + e.tag + ">",
+		})
+		return
+	}
+	if existing := string(data[e.inner.start:e.inner.end]); existing == value || Unescape(existing) == Unescape(value) {
+		return
+	}
+	w.edits = append(w.edits, edit{span: e.inner, text: value})
+}
+
+// insert adds the given element to the end of parent element
+func (w *rewriter) insert(parent *element, v interface{}) error {
+	indent, unit := w.indents(parent)
+	text, err := xml.MarshalIndent(v, indent, unit)
+	if err != nil {
+		return err
+	}
+
+	data := w.doc.data
+	if parent.selfClosing(data) {
+		// <tag attrs/> -> <tag attrs>\n  text\n</tag>
+		w.edits = append(w.edits, edit{
+			span: span{start: parent.inner.start - 2, end: parent.inner.start},
+			text: ">\n" + string(text) + "\n" + parent.indent + "</" + parent.tag + ">",
+		})
+		return nil
+	}
+
+	// inserting the element on a new line right before the end tag of parent,
+	// so the end tag keeps its own line and indentation
+	at := parent.inner.end
+	prefix, suffix := "", "\n"
+	if indent := lineIndent(data, at); indent != "" || (at > 0 && data[at-1] == '\n') {
+		at -= int64(len(indent))
+	} else {
+		prefix, suffix = "\n", "\n"+parent.indent
+	}
+	w.edits = append(w.edits, edit{span: span{start: at, end: at}, text: prefix + string(text) + suffix})
+	return nil
+}
+
+// declareXLIFF adds the declaration of the xliff namespace to the root tag if edits add
+// <xliff:g> tags and the document doesn't declare it yet, the edit goes first as it may
+// be placed at the same offset as the edit of the self-closing root tag
+func (w *rewriter) declareXLIFF() {
+	if w.doc.xliff {
+		return
+	}
+	uses := false
+	for _, e := range w.edits {
+		if strings.Contains(e.text, "<xliff:") {
+			uses = true
+		}
+	}
+	if !uses {
+		return
+	}
+
+	// the offset of ">" or "/>" of the root start tag
+	at := w.doc.root.inner.start - 1
+	if w.doc.root.selfClosing(w.doc.data) {
+		at--
+	}
+	declaration := edit{span: span{start: at, end: at}, text: ` xmlns:xliff="` + XLIFFNamespace + `"`}
+	w.edits = append([]edit{declaration}, w.edits...)
+}
+
+// indents returns the indentation of children of the given element and the indentation unit
+func (w *rewriter) indents(parent *element) (indent string, unit string) {
+	unit = w.doc.unit
+	if unit == "" {
+		unit = "\t"
+	}
+	if len(parent.items) > 0 && parent.items[0].indent != "" {
+		return parent.items[0].indent, unit
+	}
+	return parent.indent + unit, unit
+}
+
+// result applies all collected edits to the document
+func (w *rewriter) result() []byte {
+	sort.SliceStable(w.edits, func(i, j int) bool { return w.edits[i].start < w.edits[j].start })
+
+	var buf bytes.Buffer
+	var last int64
+	for _, e := range w.edits {
+		buf.Write(w.doc.data[last:e.start])
+		buf.WriteString(e.text)
+		last = e.end
+	}
+	buf.Write(w.doc.data[last:])
+	return buf.Bytes()
+}
+
+// rewriteXML updates values of resources in the given xml document with values of resources
+// with the same names from the given ResourcesEntry and adds missing resources to the end,
+// comments, formatting, attributes and other elements of the document are left as is,
+// the xliff namespace is declared if new values use <xliff:g> tags, changed is false if
+// nothing was updated
+func rewriteXML(data []byte, from ResourcesEntry) (res []byte, changed bool, err error) {
+	doc, err := scanDocument(data)
+	if err != nil {
+		return nil, false, err
+	}
+	w := &rewriter{doc: doc}
+
+	for _, ref := range from.refs() {
+		switch ref.tag {
+		case "string":
+			entry := from.Strings[ref.index]
+			if e, ok := doc.resources[resourceKey(ref.tag, entry.Name)]; ok {
+				w.setValue(e, entry.Value)
+				continue
+			}
+			err = w.insert(doc.root, entry)
+		case "plurals":
+			entry := from.Plurals[ref.index]
+			e, ok := doc.resources[resourceKey(ref.tag, entry.Name)]
+			if !ok {
+				err = w.insert(doc.root, entry)
+				break
+			}
+			for _, item := range entry.Items {
+				if itemElem := e.item(item.Quantity); itemElem != nil {
+					w.setValue(itemElem, item.Value)
+					continue
+				}
+				if err = w.insert(e, item); err != nil {
+					break
+				}
+			}
+		case "string-array":
+			entry := from.Arrays[ref.index]
+			e, ok := doc.resources[resourceKey(ref.tag, entry.Name)]
+			if !ok {
+				err = w.insert(doc.root, entry)
+				break
+			}
+			for _, item := range entry.Items {
+				if itemElem := e.item(strconv.Itoa(item.index)); itemElem != nil {
+					w.setValue(itemElem, item.Value)
+					continue
+				}
+				if err = w.insert(e, item); err != nil {
+					break
+				}
+			}
+		}
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if len(w.edits) == 0 {
+		return data, false, nil
+	}
+	w.declareXLIFF()
+	return w.result(), true, nil
+}
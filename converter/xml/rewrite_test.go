@@ -0,0 +1,118 @@
+package xml
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteXML(t *testing.T) {
+	data := `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:tools="http://schemas.android.com/tools">
+    <!-- Main screen -->
+    <string name="title" tools:ignore="MissingTranslation">Title</string>
+    <string name="empty"/>
+
+    <plurals name="apples">
+        <item quantity="one">%d apple</item>
+    </plurals>
+    <color name="accent">#FF0000</color>
+    <string-array name="planets">
+        <item>Mercury</item>
+    </string-array>
+</resources>
+`
+	res, changed, err := rewriteXML([]byte(data), convertDictionaryToResources(general.DictionaryOf(
+		"title", "New title",
+		"empty", "Not empty",
+		"apples#one", "%d apple",
+		"apples#other", "%d apples",
+		"planets[0]", "Mercury",
+		"planets[1]", "Venus",
+		"new_str", "New string",
+	)))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:tools="http://schemas.android.com/tools">
+    <!-- Main screen -->
+    <string name="title" tools:ignore="MissingTranslation">New title</string>
+    <string name="empty">Not empty</string>
+
+    <plurals name="apples">
+        <item quantity="one">%d apple</item>
+        <item quantity="other">%d apples</item>
+    </plurals>
+    <color name="accent">#FF0000</color>
+    <string-array name="planets">
+        <item>Mercury</item>
+        <item>Venus</item>
+    </string-array>
+    <string name="new_str">New string</string>
+</resources>
+`, string(res))
+
+	_, changed, err = rewriteXML(res, convertDictionaryToResources(general.DictionaryOf(
+		"title", "New title",
+		"planets[0]", "Mercury",
+		"planets[1]", "Venus",
+	)))
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestRewriteCompactXML(t *testing.T) {
+	res, changed, err := rewriteXML([]byte(`<resources><string name="a">A</string></resources>`),
+		convertDictionaryToResources(general.DictionaryOf(
+			"a", "B",
+			"c", "C",
+		)))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, "<resources><string name=\"a\">B</string>\n\t<string name=\"c\">C</string>\n</resources>", string(res))
+
+	res, _, err = rewriteXML([]byte(`<resources/>`), convertDictionaryToResources(general.DictionaryOf(
+		"a", "A",
+	)))
+	require.NoError(t, err)
+	assert.Equal(t, "<resources>\n\t<string name=\"a\">A</string>\n</resources>", string(res))
+}
+
+func TestRewriteXLIFFNamespace(t *testing.T) {
+	data := `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="title">Title</string>
+</resources>
+`
+	res, changed, err := rewriteXML([]byte(data), convertDictionaryToResources(general.DictionaryOf(
+		"title", "Title",
+		"items", `<xliff:g id="count">%d</xliff:g> Elemente`,
+	)))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<resources xmlns:xliff="`+XLIFFNamespace+`">
+    <string name="title">Title</string>
+    <string name="items"><xliff:g id="count">%d</xliff:g> Elemente</string>
+</resources>
+`, string(res))
+	_, err = scanDocument(res)
+	require.NoError(t, err)
+
+	// the declaration is not repeated
+	res, changed, err = rewriteXML(res, convertDictionaryToResources(general.DictionaryOf(
+		"items", `<xliff:g id="count">%d</xliff:g> Dinge`,
+	)))
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, 1, strings.Count(string(res), "xmlns:xliff"))
+
+	res, _, err = rewriteXML([]byte(`<resources/>`), convertDictionaryToResources(general.DictionaryOf(
+		"items", `<xliff:g id="count">%d</xliff:g> items`,
+	)))
+	require.NoError(t, err)
+	assert.Equal(t, "<resources xmlns:xliff=\""+XLIFFNamespace+"\">\n\t<string name=\"items\"><xliff:g id=\"count\">%d</xliff:g> items</string>\n</resources>", string(res))
+}
@@ -5,14 +5,18 @@ package xml
 
 import (
 	"encoding/xml"
+	"fmt"
 	"github.com/Semior001/androidstringstocsv/converter/general"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
 const (
+	// ValuesFolder defines the default values folder with base language strings
+	ValuesFolder = "values"
 	// ValuesPrefix defines the default prefix for values folder
 	ValuesPrefix = "values-"
 	// StringsFilename defines the default filename for android string constants file
@@ -23,28 +27,240 @@ const (
 
 // StringEntry struct defines a node of <string></string> tag in xml file
 type StringEntry struct {
-	XMLName xml.Name `xml:"string"`    // name of xml tag
-	Name    string   `xml:"name,attr"` // name attribute of xml tag
-	Value   string   `xml:",innerxml"` // value of xml string tag
+	XMLName      xml.Name `xml:"string"`                      // name of xml tag
+	Name         string   `xml:"name,attr"`                   // name attribute of xml tag
+	Translatable string   `xml:"translatable,attr,omitempty"` // translatable attribute of xml tag
+	Value        string   `xml:",innerxml"`                   // value of xml string tag
+}
+
+// Options defines the options of reading and writing the "res" folder
+type Options struct {
+	// BaseLanguage defines the language code of strings in the default "values" folder
+	BaseLanguage string
+	// IncludeUntranslatable defines whether to read strings marked with translatable="false"
+	IncludeUntranslatable bool
+	// Merge defines whether to update existing xml files in the "res" folder instead
+	// of creating new ones
+	Merge bool
+	// Raw defines whether to keep values of strings as they are written in xml files,
+	// with android escape sequences and quotes, instead of unescaping them on reading
+	// and escaping on writing (see Unescape and Escape)
+	Raw bool
+}
+
+// PluralItem struct defines a node of <item></item> tag inside of <plurals></plurals> tag
+type PluralItem struct {
+	XMLName  xml.Name `xml:"item"`          // name of xml tag
+	Quantity string   `xml:"quantity,attr"` // quantity attribute of xml tag
+	Value    string   `xml:",innerxml"`     // value of xml item tag
+}
+
+// PluralsEntry struct defines a node of <plurals></plurals> tag in xml file
+type PluralsEntry struct {
+	XMLName xml.Name     `xml:"plurals"`   // name of xml tag
+	Name    string       `xml:"name,attr"` // name attribute of xml tag
+	Items   []PluralItem `xml:"item"`      // quantity items of plurals
+}
+
+// ArrayItem struct defines a node of <item></item> tag inside of <string-array></string-array> tag
+type ArrayItem struct {
+	XMLName xml.Name `xml:"item"`      // name of xml tag
+	Value   string   `xml:",innerxml"` // value of xml item tag
+
+	index int // index of item from its dictionary code, e.g. 2 for "planets[2]"
+}
+
+// StringArrayEntry struct defines a node of <string-array></string-array> tag in xml file
+type StringArrayEntry struct {
+	XMLName xml.Name    `xml:"string-array"` // name of xml tag
+	Name    string      `xml:"name,attr"`    // name attribute of xml tag
+	Items   []ArrayItem `xml:"item"`         // items of array in their order
+}
+
+// RawEntry struct defines a node of any other tag in xml file, e.g. <color></color>,
+// that is kept as is
+type RawEntry struct {
+	XMLName xml.Name   // name of xml tag
+	Attrs   []xml.Attr `xml:",any,attr"` // attributes of xml tag
+	Value   string     `xml:",innerxml"` // value of xml tag
 }
 
 // ResourcesEntry struct defines a node of <resources></resources> tag in xml file
 type ResourcesEntry struct {
-	XMLName xml.Name      `xml:"resources"` // name of xml tag
-	Strings []StringEntry `xml:"string"`    // strings itself
+	XMLName xml.Name           `xml:"resources"`    // name of xml tag
+	Strings []StringEntry      `xml:"string"`       // strings itself
+	Plurals []PluralsEntry     `xml:"plurals"`      // plurals resources
+	Arrays  []StringArrayEntry `xml:"string-array"` // string-array resources
+	Others  []RawEntry         `xml:",any"`         // other resources, e.g. colors and dimens
+
+	order    []resourceRef          // resources in the order of xml file
+	comments map[resourceRef]string // comments right before resources
+}
+
+// resourceRef refers to a single resource of ResourcesEntry by the name
+// of its xml tag and its index in the corresponding slice
+type resourceRef struct {
+	tag   string
+	index int
+}
+
+// refs returns references to resources in the order of xml file, if the order is
+// unknown, strings go first, then plurals, then string arrays, then other resources
+func (r *ResourcesEntry) refs() (refs []resourceRef) {
+	if len(r.order) == len(r.Strings)+len(r.Plurals)+len(r.Arrays)+len(r.Others) {
+		return r.order
+	}
+	for i := range r.Strings {
+		refs = append(refs, resourceRef{tag: "string", index: i})
+	}
+	for i := range r.Plurals {
+		refs = append(refs, resourceRef{tag: "plurals", index: i})
+	}
+	for i := range r.Arrays {
+		refs = append(refs, resourceRef{tag: "string-array", index: i})
+	}
+	for i := range r.Others {
+		refs = append(refs, resourceRef{tag: "", index: i})
+	}
+	return
+}
+
+// UnmarshalXML decodes <resources></resources> tag and remembers the order of its resources
+// and comments right before them, comments separated from resources by blank lines are skipped
+func (r *ResourcesEntry) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	r.XMLName = start.Name
+	r.comments = map[resourceRef]string{}
+	comment := ""
+	for {
+		token, err := d.Token()
+		if err != nil {
+			return err
+		}
+
+		switch t := token.(type) {
+		case xml.Comment:
+			comment = strings.TrimSpace(string(t))
+		case xml.CharData:
+			if strings.Count(string(t), "\n") > 1 {
+				comment = ""
+			}
+		case xml.StartElement:
+			var ref resourceRef
+			switch t.Name.Local {
+			case "string":
+				var entry StringEntry
+				if err = d.DecodeElement(&entry, &t); err != nil {
+					return err
+				}
+				ref = resourceRef{tag: "string", index: len(r.Strings)}
+				r.Strings = append(r.Strings, entry)
+			case "plurals":
+				var entry PluralsEntry
+				if err = d.DecodeElement(&entry, &t); err != nil {
+					return err
+				}
+				ref = resourceRef{tag: "plurals", index: len(r.Plurals)}
+				r.Plurals = append(r.Plurals, entry)
+			case "string-array":
+				var entry StringArrayEntry
+				if err = d.DecodeElement(&entry, &t); err != nil {
+					return err
+				}
+				ref = resourceRef{tag: "string-array", index: len(r.Arrays)}
+				r.Arrays = append(r.Arrays, entry)
+			default:
+				var entry RawEntry
+				if err = d.DecodeElement(&entry, &t); err != nil {
+					return err
+				}
+				ref = resourceRef{tag: "", index: len(r.Others)}
+				r.Others = append(r.Others, entry)
+			}
+			r.order = append(r.order, ref)
+			if comment != "" {
+				r.comments[ref] = comment
+				comment = ""
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+// XLIFFNamespace defines the namespace of <xliff:g> tags, that mark untranslatable parts of strings
+const XLIFFNamespace = "urn:oasis:names:tc:xliff:document:1.2"
+
+// usesXLIFF reports whether values of any resources contain <xliff:g> tags
+func (r ResourcesEntry) usesXLIFF() bool {
+	var values []string
+	for _, entry := range r.Strings {
+		values = append(values, entry.Value)
+	}
+	for _, entry := range r.Plurals {
+		for _, item := range entry.Items {
+			values = append(values, item.Value)
+		}
+	}
+	for _, entry := range r.Arrays {
+		for _, item := range entry.Items {
+			values = append(values, item.Value)
+		}
+	}
+	for _, value := range values {
+		if strings.Contains(value, "<xliff:") {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalXML encodes <resources></resources> tag with resources in the order of xml file,
+// the xliff namespace is declared if any resource uses <xliff:g> tags
+func (r ResourcesEntry) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	start = xml.StartElement{Name: xml.Name{Local: "resources"}}
+	if r.usesXLIFF() {
+		start.Attr = []xml.Attr{{Name: xml.Name{Local: "xmlns:xliff"}, Value: XLIFFNamespace}}
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	for _, ref := range r.refs() {
+		var err error
+		switch ref.tag {
+		case "string":
+			err = e.Encode(r.Strings[ref.index])
+		case "plurals":
+			err = e.Encode(r.Plurals[ref.index])
+		case "string-array":
+			err = e.Encode(r.Arrays[ref.index])
+		default:
+			err = e.Encode(r.Others[ref.index])
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return e.EncodeToken(start.End())
+}
+
+// quantityIndex returns the position of the given quantity in the plurals resource
+func quantityIndex(quantity string) int {
+	for i, q := range general.PluralQuantities {
+		if q == quantity {
+			return i
+		}
+	}
+	return len(general.PluralQuantities)
 }
 
 // ReadXMLFile unmarshals structure of strings.xml file and returns its content
 func ReadXMLFile(path string) (r *ResourcesEntry, err error) {
-	var reader *os.File
 	var byteArray []byte
 	var res ResourcesEntry
 
-	if reader, err = os.Open(path); err != nil {
-		return nil, err
-	}
-
-	if byteArray, err = ioutil.ReadAll(reader); err != nil {
+	if byteArray, err = ioutil.ReadFile(path); err != nil {
 		return nil, err
 	}
 
@@ -55,82 +271,420 @@ func ReadXMLFile(path string) (r *ResourcesEntry, err error) {
 
 // WriteToXMLFile marshals and writes xml structure of ResourcesEntry to the specified file
 func (r *ResourcesEntry) WriteToXMLFile(path string) (file *os.File, err error) {
-	file, err = os.Create(path)
-	if err != nil {
-		return
-	}
 	byteArray, err := xml.MarshalIndent(*r, "", "	")
 	if err != nil {
 		return nil, err
 	}
-	byteArray = []byte(xml.Header + string(byteArray))
-	err = ioutil.WriteFile(path, byteArray, ExportFileMode)
-	return file, err
+	return general.CreateFile(path, []byte(xml.Header+string(byteArray)))
 }
 
-// ConvertToDictionary converts the given ResourcesEntry to the dictionary map[code]translation
-func (r *ResourcesEntry) ConvertToDictionary() (d general.Dictionary) {
-	d = make(general.Dictionary)
+// ConvertToDictionary converts the given ResourcesEntry to the dictionary map[code]translation,
+// codes go in the order of xml file
+func (r *ResourcesEntry) ConvertToDictionary() (d *general.Dictionary) {
+	d = general.NewDictionary()
 
-	for _, entry := range (*r).Strings {
-		d[entry.Name] = entry.Value
+	for _, ref := range r.refs() {
+		switch ref.tag {
+		case "string":
+			entry := (*r).Strings[ref.index]
+			d.Set(entry.Name, entry.Value)
+		case "plurals":
+			plurals := (*r).Plurals[ref.index]
+			for _, item := range plurals.Items {
+				d.Set(general.PluralCode(plurals.Name, item.Quantity), item.Value)
+			}
+		case "string-array":
+			array := (*r).Arrays[ref.index]
+			for i, item := range array.Items {
+				d.Set(general.ArrayCode(array.Name, i), item.Value)
+			}
+		}
 	}
 
 	return
 }
 
-// convertDictionaryToResources converts the given dictionary map[code]translation to the ResourcesEntry
-func convertDictionaryToResources(d general.Dictionary) (r ResourcesEntry) {
+// convertDictionaryToResources converts the given dictionary map[code]translation to the ResourcesEntry,
+// resources go in the order of the first appearance of their codes in the dictionary
+func convertDictionaryToResources(d *general.Dictionary) (r ResourcesEntry) {
 	r = ResourcesEntry{
 		Strings: []StringEntry{},
 	}
-	for name, value := range d {
+	plurals := map[string]int{} // name of plurals resource -> its index in r.Plurals
+	arrays := map[string]int{}  // name of string-array resource -> its index in r.Arrays
+	for _, name := range d.Codes() {
+		value := d.Get(name)
+		if arrayName, index, ok := general.ParseArrayCode(name); ok {
+			i, exists := arrays[arrayName]
+			if !exists {
+				i = len(r.Arrays)
+				arrays[arrayName] = i
+				r.Arrays = append(r.Arrays, StringArrayEntry{Name: arrayName})
+				r.order = append(r.order, resourceRef{tag: "string-array", index: i})
+			}
+			r.Arrays[i].Items = append(r.Arrays[i].Items, ArrayItem{Value: value, index: index})
+			continue
+		}
+		if pluralsName, quantity, ok := general.ParsePluralCode(name); ok {
+			i, exists := plurals[pluralsName]
+			if !exists {
+				i = len(r.Plurals)
+				plurals[pluralsName] = i
+				r.Plurals = append(r.Plurals, PluralsEntry{Name: pluralsName})
+				r.order = append(r.order, resourceRef{tag: "plurals", index: i})
+			}
+			r.Plurals[i].Items = append(r.Plurals[i].Items, PluralItem{
+				Quantity: quantity,
+				Value:    value,
+			})
+			continue
+		}
+		r.order = append(r.order, resourceRef{tag: "string", index: len(r.Strings)})
 		r.Strings = append(r.Strings, StringEntry{
 			Name:  name,
 			Value: value,
 		})
 	}
+
+	for _, p := range r.Plurals {
+		sort.SliceStable(p.Items, func(i, j int) bool {
+			return quantityIndex(p.Items[i].Quantity) < quantityIndex(p.Items[j].Quantity)
+		})
+	}
+
+	for _, a := range r.Arrays {
+		sort.SliceStable(a.Items, func(i, j int) bool { return a.Items[i].index < a.Items[j].index })
+	}
 	return
 }
 
 // exportDictionaryToXML writes the given dictionary to the xml file at the given path
-func exportDictionaryToXML(path string, d general.Dictionary) (files *os.File, err error) {
+func exportDictionaryToXML(path string, d *general.Dictionary) (files *os.File, err error) {
 	r := convertDictionaryToResources(d)
 	files, err = r.WriteToXMLFile(path)
 	return
 }
 
-// WriteResFolder writes the given set of dictionaries to the res folder at the given path
-func WriteResFolder(path string, dicts general.Dictionaries) (files []*os.File, err error) {
-	err = os.Mkdir(path, ExportFileMode)
+// descriptions returns comments right before resources by codes of strings, comments
+// of plurals and string arrays are returned for each of their items
+func (r *ResourcesEntry) descriptions() (d *general.Dictionary) {
+	d = general.NewDictionary()
+	for _, ref := range r.refs() {
+		comment, ok := r.comments[ref]
+		if !ok {
+			continue
+		}
+		switch ref.tag {
+		case "string":
+			d.Set(r.Strings[ref.index].Name, comment)
+		case "plurals":
+			plurals := r.Plurals[ref.index]
+			for _, item := range plurals.Items {
+				d.Set(general.PluralCode(plurals.Name, item.Quantity), comment)
+			}
+		case "string-array":
+			array := r.Arrays[ref.index]
+			for i := range array.Items {
+				d.Set(general.ArrayCode(array.Name, i), comment)
+			}
+		}
+	}
+	return d
+}
+
+// untranslatable returns names of strings marked with translatable="false"
+func (r *ResourcesEntry) untranslatable() (names []string) {
+	for _, entry := range (*r).Strings {
+		if entry.Translatable == "false" {
+			names = append(names, entry.Name)
+		}
+	}
+	return
+}
+
+// markUntranslatable sets translatable="false" to all strings, that are
+// marked as untranslatable in the given dictionary of attributes
+func (r *ResourcesEntry) markUntranslatable(attrs *general.Dictionary) {
+	for i, entry := range (*r).Strings {
+		if attrs.Get(entry.Name) == "false" {
+			(*r).Strings[i].Translatable = "false"
+		}
+	}
+}
+
+// withoutUntranslatable returns the copy of the given dictionary without strings,
+// that are marked as untranslatable in the given dictionary of attributes
+func withoutUntranslatable(d *general.Dictionary, attrs *general.Dictionary) (res *general.Dictionary) {
+	res = general.NewDictionary()
+	for _, name := range d.Codes() {
+		if attrs.Get(name) == "false" {
+			continue
+		}
+		res.Set(name, d.Get(name))
+	}
+	return
+}
+
+// withoutIncompleteArrays returns the copy of the given dictionary of translations without string
+// arrays, that miss some items of the array in the given base dictionary or have gaps between
+// indexes of items, as items of such arrays would shift on devices, e.g. the only translated
+// item "planets[2]" would become the first one, devices use the base array instead, items
+// of the given existing dictionary, e.g. of the folder being merged into, fill the gaps
+func withoutIncompleteArrays(d *general.Dictionary, base *general.Dictionary,
+	existing *general.Dictionary) (res *general.Dictionary) {
+	sizes := map[string]int{} // name of string-array resource -> the number of its items
+	for _, dict := range []*general.Dictionary{base, d, existing} {
+		for _, code := range dict.Codes() {
+			if name, index, ok := general.ParseArrayCode(code); ok && index >= sizes[name] {
+				sizes[name] = index + 1
+			}
+		}
+	}
+
+	incomplete := map[string]bool{}
+	for _, code := range d.Codes() {
+		name, _, ok := general.ParseArrayCode(code)
+		if !ok {
+			continue
+		}
+		for i := 0; i < sizes[name]; i++ {
+			code := general.ArrayCode(name, i)
+			if _, ok := d.Lookup(code); ok {
+				continue
+			}
+			if _, ok := existing.Lookup(code); !ok {
+				incomplete[name] = true
+				break
+			}
+		}
+	}
+
+	res = general.NewDictionary()
+	for _, code := range d.Codes() {
+		if name, _, ok := general.ParseArrayCode(code); ok && incomplete[name] {
+			continue
+		}
+		res.Set(code, d.Get(code))
+	}
+	return
+}
+
+// valuesFolderName returns the name of the values folder for the given language code,
+// strings of the base language are placed to the default "values" folder, language codes
+// in format of android locale qualifiers are normalized, e.g. "b+pt+BR" -> "pt-rBR"
+func valuesFolderName(langCode string, baseLang string) string {
+	if langCode == baseLang {
+		return ValuesFolder
+	}
+	if l, ok := general.ParseLocaleQualifier(langCode); ok {
+		return ValuesPrefix + l.Qualifier()
+	}
+	return ValuesPrefix + langCode
+}
+
+// fileDictionary defines strings, that are written to a single xml file of values folder
+type fileDictionary struct {
+	filename string
+	dict     *general.Dictionary
+}
+
+// splitByFile splits the given dictionary to dictionaries of xml files listed in the given dictionary
+// of file names, strings without file name go to the default strings.xml file
+func splitByFile(d *general.Dictionary, files *general.Dictionary) (res []fileDictionary) {
+	indexes := map[string]int{} // name of file -> its index in res
+	for _, code := range d.Codes() {
+		filename := files.Get(code)
+		if filename == "" {
+			filename = StringsFilename
+		}
+		i, exists := indexes[filename]
+		if !exists {
+			i = len(res)
+			indexes[filename] = i
+			res = append(res, fileDictionary{filename: filename, dict: general.NewDictionary()})
+		}
+		res[i].dict.Set(code, d.Get(code))
+	}
+	return
+}
+
+// WriteResFolder writes the given set of dictionaries to the res folder at the given path,
+// dictionary of the base language is written to the default "values" folder, untranslatable
+// strings are written only to the default "values" folder, strings of other languages go in
+// the order of the base language, partly translated string arrays are not written to
+// folders of other languages (see withoutIncompleteArrays), strings are written to xml files listed in the
+// general.FileMeta dictionary or to the default strings.xml file, values are escaped
+// unless opts.Raw is set. Nothing is written if markup of strings is invalid (see ValidateMarkup).
+//
+// In merge mode existing folders are reused and existing xml files are updated: strings
+// with the same names get new values, missing strings are added, comments, formatting,
+// attributes and other resources are left untouched and files without changes are not
+// rewritten. Strings, that already exist in xml files of the default "values" folder, are
+// written to the files with the same names. Translations go to the existing folders with the
+// same locale, e.g. "values-b+pt+BR" for "pt-rBR", so no duplicate folders are created.
+// Items of string arrays replace the items with the same indexes, existing items complete
+// partly translated arrays.
+func WriteResFolder(path string, dicts general.Dictionaries, opts Options) (files []*os.File, err error) {
+	err = ValidateMarkup(dicts, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	err = mkdir(path, opts.Merge)
 	if err != nil {
 		return nil, err
 	}
 
 	files = []*os.File{}
 
-	for langCode, d := range dicts {
-		valPath := filepath.Join(path, ValuesPrefix+langCode)
+	attrs := dicts[general.TranslatableMeta]
+	filenames := dicts[general.FileMeta]
+	base := dicts[opts.BaseLanguage]
+	folders := map[string]string{}
 
-		err = os.Mkdir(valPath, ExportFileMode)
+	if opts.Merge {
+		attrs, filenames, err = withExistingAttrs(attrs, filenames, filepath.Join(path, ValuesFolder))
 		if err != nil {
 			return
 		}
+		folders, err = existingValuesFolders(path)
+		if err != nil {
+			return
+		}
+	}
 
-		var file *os.File
+	for _, langCode := range general.SortedLanguages(dicts, opts.BaseLanguage) {
+		if general.IsMeta(langCode) {
+			continue
+		}
+		folderName := valuesFolderName(langCode, opts.BaseLanguage)
+		if existing, ok := folders[folderName]; ok {
+			folderName = existing
+		}
+		valPath := filepath.Join(path, folderName)
+
+		d := dicts[langCode]
+		if langCode != opts.BaseLanguage {
+			var existing *general.Dictionary
+			if opts.Merge {
+				existing, err = existingStrings(valPath)
+				if err != nil {
+					return
+				}
+			}
+			d = withoutIncompleteArrays(withoutUntranslatable(d.OrderedLike(base), attrs), base, existing)
+		}
+		if !opts.Raw {
+			d = mapValues(d, Escape)
+		}
 
-		file, err = exportDictionaryToXML(filepath.Join(valPath, StringsFilename), d)
-		files = append(files, file)
+		err = mkdir(valPath, opts.Merge)
 		if err != nil {
 			return
 		}
+
+		for _, fd := range splitByFile(d, filenames) {
+			r := convertDictionaryToResources(fd.dict)
+			if langCode == opts.BaseLanguage {
+				r.markUntranslatable(attrs)
+			}
+
+			filePath := filepath.Join(valPath, fd.filename)
+
+			var file *os.File
+
+			if opts.Merge {
+				file, err = mergeXMLFile(filePath, r)
+			} else {
+				file, err = r.WriteToXMLFile(filePath)
+			}
+			if file != nil {
+				files = append(files, file)
+			}
+			if err != nil {
+				return
+			}
+		}
 	}
 
 	return
 }
 
-// ReadResFolder reads and unmarshals all strings.xml files in the "res" folder
-func ReadResFolder(path string) (dicts general.Dictionaries, err error) {
+// valuesFolder defines strings read from all xml files of a single values folder
+type valuesFolder struct {
+	dict           *general.Dictionary // strings of all xml files
+	files          *general.Dictionary // names of xml files for strings not from strings.xml
+	untranslatable []string            // names of strings marked with translatable="false"
+	descriptions   *general.Dictionary // comments right before strings
+}
+
+// readValuesFolder reads and unmarshals all xml files in the values folder at the given path,
+// strings from the default strings.xml file go first, then strings from other files in
+// alphabetical order of file names
+func readValuesFolder(path string) (folder valuesFolder, err error) {
+	contents, err := ioutil.ReadDir(path)
+	if err != nil {
+		return folder, err
+	}
+
+	filenames := []string{}
+	for _, entry := range contents {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".xml" {
+			continue
+		}
+		if entry.Name() == StringsFilename {
+			filenames = append([]string{entry.Name()}, filenames...)
+			continue
+		}
+		filenames = append(filenames, entry.Name())
+	}
+
+	folder = valuesFolder{
+		dict:         general.NewDictionary(),
+		files:        general.NewDictionary(),
+		descriptions: general.NewDictionary(),
+	}
+
+	for _, filename := range filenames {
+		var res *ResourcesEntry
+		// reading xml structure
+		res, err = ReadXMLFile(filepath.Join(path, filename))
+		if err != nil {
+			return folder, err
+		}
+
+		d := res.ConvertToDictionary()
+		for _, code := range d.Codes() {
+			folder.dict.Set(code, d.Get(code))
+			if filename != StringsFilename {
+				folder.files.Set(code, filename)
+			}
+		}
+		folder.untranslatable = append(folder.untranslatable, res.untranslatable()...)
+
+		descriptions := res.descriptions()
+		for _, code := range descriptions.Codes() {
+			folder.descriptions.Set(code, descriptions.Get(code))
+		}
+	}
+
+	return folder, nil
+}
+
+// ReadResFolder reads and unmarshals all xml files in values folders of the "res" folder,
+// strings from the default "values" folder are stored under the base language code, strings
+// from folders with locale qualifier are stored under the qualifier, e.g. "pt-rBR" or
+// "b+sr+Latn", folders with other qualifiers, e.g. "values-night", are skipped,
+// names of xml files other than strings.xml are listed in the general.FileMeta dictionary,
+// comments right before strings in the default "values" folder are listed in the
+// general.DescriptionMeta dictionary,
+// untranslatable strings are skipped unless opts.IncludeUntranslatable is set, in that
+// case they are read from the default "values" folder and listed in the
+// general.TranslatableMeta dictionary, strings marked as untranslatable in the default
+// "values" folder are skipped in all other folders even without the attribute,
+// values are unescaped unless opts.Raw is set, folders with the same locale, e.g.
+// "values-b+pt+BR" and "values-pt-rBR", are reported as an error
+func ReadResFolder(path string, opts Options) (dicts general.Dictionaries, err error) {
 	contents, err := ioutil.ReadDir(path)
 	if err != nil {
 		return nil, err
@@ -138,24 +692,100 @@ func ReadResFolder(path string) (dicts general.Dictionaries, err error) {
 
 	dicts = make(general.Dictionaries)
 
+	folders := map[string]valuesFolder{}
+	folderNames := map[string]string{} // language code -> name of its values folder
+	langCodes := []string{}
 	for _, entry := range contents {
-		// skip if it is not a directory, that starts with "values-"
-		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), ValuesPrefix) {
+		// skip if it is not a directory, that is "values" or "values-" with locale qualifier only
+		if !entry.IsDir() {
 			continue
 		}
 
-		var res *ResourcesEntry
-		// reading xml structure
-		res, err = ReadXMLFile(filepath.Join(path, entry.Name(), StringsFilename))
+		locale, base, ok := ParseValuesFolder(entry.Name())
+		if !ok {
+			continue
+		}
 
+		langCode := locale.Qualifier()
+		if base {
+			langCode = opts.BaseLanguage
+		}
+		if name, ok := folderNames[langCode]; ok {
+			return nil, fmt.Errorf("folders %s and %s have strings of the same language %s",
+				name, entry.Name(), langCode)
+		}
+		folderNames[langCode] = entry.Name()
+
+		var folder valuesFolder
+		folder, err = readValuesFolder(filepath.Join(path, entry.Name()))
 		if err != nil {
 			return
 		}
+		folders[langCode] = folder
+		langCodes = append(langCodes, langCode)
+	}
 
-		langCode := entry.Name()[len(ValuesPrefix):]
+	// strings marked as untranslatable in the default "values" folder are
+	// skipped in all folders, even if translations don't repeat the attribute
+	baseUntranslatable := folders[opts.BaseLanguage].untranslatable
 
-		dicts[langCode] = (*res).ConvertToDictionary()
+	for _, langCode := range langCodes {
+		folder := folders[langCode]
+		d := folder.dict
+		untranslatable := folder.untranslatable
+		if langCode != opts.BaseLanguage {
+			untranslatable = append(append([]string{}, baseUntranslatable...), untranslatable...)
+		}
+		for _, name := range untranslatable {
+			if !opts.IncludeUntranslatable || langCode != opts.BaseLanguage {
+				d.Delete(name)
+				continue
+			}
+			if dicts[general.TranslatableMeta] == nil {
+				dicts[general.TranslatableMeta] = general.NewDictionary()
+			}
+			dicts[general.TranslatableMeta].Set(name, "false")
+		}
+
+		for _, code := range folder.files.Codes() {
+			if _, ok := d.Lookup(code); !ok {
+				continue
+			}
+			if dicts[general.FileMeta] == nil {
+				dicts[general.FileMeta] = general.NewDictionary()
+			}
+			if _, ok := dicts[general.FileMeta].Lookup(code); !ok {
+				dicts[general.FileMeta].Set(code, folder.files.Get(code))
+			}
+		}
+
+		for _, code := range folder.descriptions.Codes() {
+			if _, ok := d.Lookup(code); !ok || langCode != opts.BaseLanguage {
+				continue
+			}
+			if dicts[general.DescriptionMeta] == nil {
+				dicts[general.DescriptionMeta] = general.NewDictionary()
+			}
+			dicts[general.DescriptionMeta].Set(code, folder.descriptions.Get(code))
+		}
+
+		if !opts.Raw {
+			d = mapValues(d, Unescape)
+		}
+
+		if d.Len() > 0 {
+			dicts[langCode] = d
+		}
 	}
 
 	return
 }
+
+// mapValues returns the copy of the given dictionary with values converted by the given function
+func mapValues(d *general.Dictionary, fn func(string) string) (res *general.Dictionary) {
+	res = general.NewDictionary()
+	for _, code := range d.Codes() {
+		res.Set(code, fn(d.Get(code)))
+	}
+	return res
+}
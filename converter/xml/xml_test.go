@@ -1,63 +1,436 @@
 package xml
 
 import (
+	"encoding/xml"
+	"io/ioutil"
 	"os"
 	"testing"
 
+	"github.com/Semior001/androidstringstocsv/converter/general"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
 func TestConvertions(t *testing.T) {
-	r := convertDictionaryToResources(map[string]string{
-		"test_str": "Test translation",
-	})
-	assert.Equal(t, ResourcesEntry{
-		Strings: []StringEntry{
-			StringEntry{
-				Name:  "test_str",
-				Value: "Test translation",
-			},
+	r := convertDictionaryToResources(general.DictionaryOf(
+		"test_str", "Test translation",
+	))
+	assert.Equal(t, []StringEntry{
+		StringEntry{
+			Name:  "test_str",
+			Value: "Test translation",
 		},
-	}, r)
+	}, r.Strings)
 
 	d := r.ConvertToDictionary()
-	assert.Equal(t, map[string]string{
-		"test_str": "Test translation",
-	}, d)
+	assert.Equal(t, general.DictionaryOf(
+		"test_str", "Test translation",
+	), d)
 }
 
 func TestReadWriteXML(t *testing.T) {
 	defer os.RemoveAll("/tmp/androidstringscsv.test")
 
-	_, err := exportDictionaryToXML("/tmp/androidstringscsv.test", map[string]string{
-		"test_str": "Test translation",
-	})
+	_, err := exportDictionaryToXML("/tmp/androidstringscsv.test", general.DictionaryOf(
+		"test_str", "Test translation",
+	))
 	require.NoError(t, err)
 	assert.FileExists(t, "/tmp/androidstringscsv.test")
 
 	readed, err := ReadXMLFile("/tmp/androidstringscsv.test")
 	require.NoError(t, err)
-	assert.Equal(t, map[string]string{
-		"test_str": "Test translation",
-	}, (*readed).ConvertToDictionary())
+	assert.Equal(t, general.DictionaryOf(
+		"test_str", "Test translation",
+	), (*readed).ConvertToDictionary())
 }
 
 func TestReadWriteRes(t *testing.T) {
 	defer os.RemoveAll("/tmp/res")
-	_, err := WriteResFolder("/tmp/res", map[string]map[string]string{
-		"tl": map[string]string{
-			"test_str": "Test translation",
-		},
-	})
+	_, err := WriteResFolder("/tmp/res", general.Dictionaries{
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+		),
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+		),
+	}, Options{BaseLanguage: "en"})
 	require.NoError(t, err)
 	assert.DirExists(t, "/tmp/res")
+	assert.FileExists(t, "/tmp/res/values/strings.xml")
+	assert.FileExists(t, "/tmp/res/values-tl/strings.xml")
+
+	dicts, err := ReadResFolder("/tmp/res", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+		),
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+		),
+	}, dicts)
+}
+
+func TestPluralsConvertions(t *testing.T) {
+	r := convertDictionaryToResources(general.DictionaryOf(
+		"apples#other", "%d apples",
+		"apples#one", "%d apple",
+	))
+	assert.Equal(t, []PluralsEntry{
+		PluralsEntry{
+			Name: "apples",
+			Items: []PluralItem{
+				PluralItem{Quantity: "one", Value: "%d apple"},
+				PluralItem{Quantity: "other", Value: "%d apples"},
+			},
+		},
+	}, r.Plurals)
+}
+
+func TestReadWritePluralsXML(t *testing.T) {
+	defer os.RemoveAll("/tmp/androidstringscsv.test")
 
-	dicts, err := ReadResFolder("/tmp/res")
+	_, err := exportDictionaryToXML("/tmp/androidstringscsv.test", general.DictionaryOf(
+		"test_str", "Test translation",
+		"apples#one", "%d apple",
+		"apples#other", "%d apples",
+	))
 	require.NoError(t, err)
-	assert.Equal(t, map[string]map[string]string{
-		"tl": map[string]string{
-			"test_str": "Test translation",
+
+	readed, err := ReadXMLFile("/tmp/androidstringscsv.test")
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"test_str", "Test translation",
+		"apples#one", "%d apple",
+		"apples#other", "%d apples",
+	), (*readed).ConvertToDictionary())
+}
+
+func TestStringArrayConvertions(t *testing.T) {
+	r := convertDictionaryToResources(general.DictionaryOf(
+		"planets[2]", "Earth",
+		"planets[0]", "Mercury",
+		"planets[1]", "Venus",
+	))
+	assert.Equal(t, []StringArrayEntry{
+		StringArrayEntry{
+			Name: "planets",
+			Items: []ArrayItem{
+				ArrayItem{Value: "Mercury", index: 0},
+				ArrayItem{Value: "Venus", index: 1},
+				ArrayItem{Value: "Earth", index: 2},
+			},
 		},
+	}, r.Arrays)
+	assert.Equal(t, general.DictionaryOf(
+		"planets[0]", "Mercury",
+		"planets[1]", "Venus",
+		"planets[2]", "Earth",
+	), r.ConvertToDictionary())
+}
+
+func TestSparseStringArrayRes(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	_, err := WriteResFolder("/tmp/res", general.Dictionaries{
+		"en": general.DictionaryOf(
+			"planets[0]", "Mercury",
+			"planets[1]", "Venus",
+			"planets[2]", "Earth",
+			"colors[0]", "Red",
+		),
+		"de": general.DictionaryOf(
+			"planets[2]", "Erde",
+			"colors[0]", "Rot",
+		),
+		"fr": general.DictionaryOf(
+			"planets[0]", "Mercure",
+			"planets[1]", "Vénus",
+			"planets[2]", "Terre",
+		),
+	}, Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+
+	dicts, err := ReadResFolder("/tmp/res", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"colors[0]", "Rot",
+	), dicts["de"])
+	assert.Equal(t, general.DictionaryOf(
+		"planets[0]", "Mercure",
+		"planets[1]", "Vénus",
+		"planets[2]", "Terre",
+	), dicts["fr"])
+}
+
+func TestXMLOrder(t *testing.T) {
+	defer os.RemoveAll("/tmp/androidstringscsv.test")
+
+	err := ioutil.WriteFile("/tmp/androidstringscsv.test", []byte(`<?xml version="1.0" encoding="utf-8"?>
+<resources>
+	<string name="title">Title</string>
+	<plurals name="apples">
+		<item quantity="one">%d apple</item>
+		<item quantity="other">%d apples</item>
+	</plurals>
+	<color name="accent">#FF0000</color>
+	<string name="about">About</string>
+	<string-array name="planets">
+		<item>Mercury</item>
+	</string-array>
+	<string name="cancel">Cancel</string>
+</resources>`), ExportFileMode)
+	require.NoError(t, err)
+
+	readed, err := ReadXMLFile("/tmp/androidstringscsv.test")
+	require.NoError(t, err)
+	d := readed.ConvertToDictionary()
+	assert.Equal(t, []string{
+		"title", "apples#one", "apples#other", "about", "planets[0]", "cancel",
+	}, d.Codes())
+
+	_, err = exportDictionaryToXML("/tmp/androidstringscsv.test", d)
+	require.NoError(t, err)
+	readed, err = ReadXMLFile("/tmp/androidstringscsv.test")
+	require.NoError(t, err)
+	assert.Equal(t, d, readed.ConvertToDictionary())
+}
+
+func TestUntranslatableRes(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	_, err := WriteResFolder("/tmp/res", general.Dictionaries{
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+			"api_key", "secret",
+		),
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+			"api_key", "secret",
+		),
+		general.TranslatableMeta: general.DictionaryOf(
+			"api_key", "false",
+		),
+	}, Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+
+	base, err := ReadXMLFile("/tmp/res/values/strings.xml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"api_key"}, base.untranslatable())
+
+	dicts, err := ReadResFolder("/tmp/res", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+		),
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+		),
+	}, dicts)
+
+	dicts, err = ReadResFolder("/tmp/res", Options{BaseLanguage: "en", IncludeUntranslatable: true})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+			"api_key", "secret",
+		),
+		"tl": general.DictionaryOf(
+			"test_str", "Test translation",
+		),
+		general.TranslatableMeta: general.DictionaryOf(
+			"api_key", "false",
+		),
 	}, dicts)
 }
+
+func TestUntranslatableInBaseOnlyRes(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	require.NoError(t, os.MkdirAll("/tmp/res/values", ExportFileMode))
+	require.NoError(t, os.MkdirAll("/tmp/res/values-de", ExportFileMode))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values/strings.xml", []byte(`<resources>
+	<string name="title">Title</string>
+	<string name="api" translatable="false">KEY</string>
+</resources>`), ExportFileMode))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values-de/strings.xml", []byte(`<resources>
+	<string name="title">Titel</string>
+	<string name="api">KEY</string>
+</resources>`), ExportFileMode))
+
+	dicts, err := ReadResFolder("/tmp/res", Options{BaseLanguage: "en", IncludeUntranslatable: true})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"title", "Title",
+			"api", "KEY",
+		),
+		"de": general.DictionaryOf(
+			"title", "Titel",
+		),
+		general.TranslatableMeta: general.DictionaryOf(
+			"api", "false",
+		),
+	}, dicts)
+}
+
+func TestReadWriteMultipleFilesRes(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	require.NoError(t, os.MkdirAll("/tmp/res/values", ExportFileMode))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values/errors.xml", []byte(`<resources>
+	<string name="error_network">Network error</string>
+</resources>`), ExportFileMode))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values/colors.xml", []byte(`<resources>
+	<color name="accent">#FF0000</color>
+</resources>`), ExportFileMode))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values/strings.xml", []byte(`<resources>
+	<string name="test_str">Test string</string>
+</resources>`), ExportFileMode))
+
+	dicts, err := ReadResFolder("/tmp/res", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"test_str", "Test string",
+			"error_network", "Network error",
+		),
+		general.FileMeta: general.DictionaryOf(
+			"error_network", "errors.xml",
+		),
+	}, dicts)
+
+	dicts["tl"] = general.DictionaryOf(
+		"error_network", "Network translation",
+		"test_str", "Test translation",
+	)
+	_, err = WriteResFolder("/tmp/res", dicts, Options{BaseLanguage: "en", Merge: true})
+	require.NoError(t, err)
+
+	readed, err := ReadXMLFile("/tmp/res/values-tl/errors.xml")
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"error_network", "Network translation",
+	), readed.ConvertToDictionary())
+
+	readed, err = ReadXMLFile("/tmp/res/values-tl/strings.xml")
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"test_str", "Test translation",
+	), readed.ConvertToDictionary())
+
+	// without the list of files, strings are written to files of the existing values folder
+	delete(dicts, general.FileMeta)
+	dicts["tl"].Set("error_network", "Updated translation")
+	_, err = WriteResFolder("/tmp/res", dicts, Options{BaseLanguage: "en", Merge: true})
+	require.NoError(t, err)
+
+	readed, err = ReadXMLFile("/tmp/res/values-tl/errors.xml")
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"error_network", "Updated translation",
+	), readed.ConvertToDictionary())
+}
+
+func TestEscapedRes(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	require.NoError(t, os.MkdirAll("/tmp/res/values", os.ModePerm))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values/strings.xml", []byte(`<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="apostrophe">Don\'t</string>
+    <string name="quoted">"Don't"</string>
+    <string name="lines">First\nSecond</string>
+    <string name="at">\@username</string>
+    <string name="amp">Tom &amp; Jerry</string>
+</resources>
+`), os.ModePerm))
+
+	dicts, err := ReadResFolder("/tmp/res", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.Dictionaries{
+		"en": general.DictionaryOf(
+			"apostrophe", "Don't",
+			"quoted", "Don't",
+			"lines", "First\nSecond",
+			"at", "@username",
+			"amp", "Tom & Jerry",
+		),
+	}, dicts)
+
+	raw, err := ReadResFolder("/tmp/res", Options{BaseLanguage: "en", Raw: true})
+	require.NoError(t, err)
+	assert.Equal(t, `"Don't"`, raw["en"].Get("quoted"))
+
+	dicts["en"].Set("apostrophe", "It's")
+	_, err = WriteResFolder("/tmp/res", dicts, Options{BaseLanguage: "en", Merge: true})
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadFile("/tmp/res/values/strings.xml")
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="apostrophe">It\'s</string>
+    <string name="quoted">"Don't"</string>
+    <string name="lines">First\nSecond</string>
+    <string name="at">\@username</string>
+    <string name="amp">Tom &amp; Jerry</string>
+</resources>
+`, string(data))
+}
+
+func TestXLIFFNamespace(t *testing.T) {
+	data, err := xml.Marshal(convertDictionaryToResources(general.DictionaryOf(
+		"count", `<xliff:g id="count">%d</xliff:g> items`,
+	)))
+	require.NoError(t, err)
+	assert.Equal(t, `<resources xmlns:xliff="`+XLIFFNamespace+`"><string name="count"><xliff:g id="count">%d</xliff:g> items</string></resources>`, string(data))
+
+	data, err = xml.Marshal(convertDictionaryToResources(general.DictionaryOf("plain", "Plain text")))
+	require.NoError(t, err)
+	assert.Equal(t, `<resources><string name="plain">Plain text</string></resources>`, string(data))
+}
+
+func TestDescriptionsRes(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	require.NoError(t, os.MkdirAll("/tmp/res/values", os.ModePerm))
+	require.NoError(t, os.MkdirAll("/tmp/res/values-de", os.ModePerm))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values/strings.xml", []byte(`<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- Main screen -->
+
+    <!-- Title of the main screen -->
+    <string name="title">Title</string>
+    <string name="subtitle">Subtitle</string>
+    <!-- Number of apples in the basket -->
+    <plurals name="apples">
+        <item quantity="one">%d apple</item>
+        <item quantity="other">%d apples</item>
+    </plurals>
+</resources>
+`), os.ModePerm))
+	require.NoError(t, ioutil.WriteFile("/tmp/res/values-de/strings.xml", []byte(`<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <!-- Titel -->
+    <string name="title">Titel</string>
+</resources>
+`), os.ModePerm))
+
+	dicts, err := ReadResFolder("/tmp/res", Options{BaseLanguage: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, general.DictionaryOf(
+		"title", "Title of the main screen",
+		"apples#one", "Number of apples in the basket",
+		"apples#other", "Number of apples in the basket",
+	), dicts[general.DescriptionMeta])
+}
+
+func TestSameLocaleFoldersRes(t *testing.T) {
+	defer os.RemoveAll("/tmp/res")
+	for _, folder := range []string{"values", "values-b+pt+BR", "values-pt-rBR"} {
+		require.NoError(t, os.MkdirAll("/tmp/res/"+folder, ExportFileMode))
+		require.NoError(t, ioutil.WriteFile("/tmp/res/"+folder+"/strings.xml",
+			[]byte(`<resources><string name="a">A</string></resources>`), ExportFileMode))
+	}
+
+	_, err := ReadResFolder("/tmp/res", Options{BaseLanguage: "en"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "values-b+pt+BR")
+	assert.Contains(t, err.Error(), "values-pt-rBR")
+}
@@ -1,57 +1,535 @@
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
-	"github.com/Semior001/androidstringstocsv/converter/xml"
 	"os"
-)
+	"path/filepath"
 
-var xmlHeader string = "<?xml version=\"1.0\" encoding=\"utf-8\"?>"
+	"github.com/Semior001/androidstringstocsv/converter/arb"
+	"github.com/Semior001/androidstringstocsv/converter/csv"
+	"github.com/Semior001/androidstringstocsv/converter/general"
+	"github.com/Semior001/androidstringstocsv/converter/ios"
+	"github.com/Semior001/androidstringstocsv/converter/po"
+	"github.com/Semior001/androidstringstocsv/converter/xliff"
+	"github.com/Semior001/androidstringstocsv/converter/xml"
+)
 
 const (
 	helpString = `asc - android strings converter by semior001
 
-Usage: asc [COMMAND] [FROM] [TO]
+Usage: asc [COMMAND] [OPTIONS] [FROM] [TO]
 
 Commands:
-	xml2csv  - convert android xml strings folders to csv file
-	csv2xml  - convert csv file to android xml "values" folders
+	xml2csv       - convert android xml strings folders to csv file
+	csv2xml       - convert csv file to android xml "values" folders
+	xml2xliff     - convert android xml strings folders to XLIFF files, one
+	                file per target language, e.g. "de.xlf"
+	xliff2xml     - convert folder with translated XLIFF files to android xml
+	                "values" folders
+	xml2ios       - convert android xml strings folders to "xx.lproj" folders
+	                with Localizable.strings and Localizable.stringsdict files
+	ios2xml       - convert "xx.lproj" folders of iOS project to android xml
+	                "values" folders
+	xml2po        - convert android xml strings folders to gettext messages.pot
+	                template and "xx.po" file per target language, string
+	                keys are kept in msgctxt, xml comments are written for
+	                translators
+	po2xml        - convert folder with translated "xx.po" files to android xml
+	                "values" folders
+	xml2arb       - convert android xml strings folders to Flutter ARB files,
+	                e.g. "app_en.arb", with descriptions and placeholders
+	                in "@key" blocks
+	arb2xml       - convert folder with Flutter ARB files to android xml
+	                "values" folders
+	xml2xcstrings - update Xcode string catalog (Localizable.xcstrings) with
+	                android xml strings, other strings and fields are kept
+	xcstrings2xml - convert Xcode string catalog to android xml "values" folders
+	csv2xcstrings - update Xcode string catalog with translations from csv
+	                file, review states are taken from "@state:xx" columns
+	xcstrings2csv - convert Xcode string catalog to csv file, review states of
+	                translations are kept in "@state:xx" columns
+	validate      - check, that translations keep format specifiers of strings
+	                of the base language, FROM is either the "res" folder or
+	                csv file, exits with non-zero status if any problems are found
+
+Options:
+	--base-lang              - language code of strings in the default "values"
+	                           folder (default "default")
+	--include-untranslatable - export strings marked with translatable="false"
+	                           (xml2csv only)
+	--merge                  - update existing "res" folder instead of creating
+	                           a new one (csv2xml only)
+	--project                - treat the path as the root of android project and
+	                           convert "res" folders of all its modules and source
+	                           sets, paths of "res" folders are kept in the
+	                           "@module" column of csv file
+	--bcp47                  - write language codes in csv header as BCP-47 tags,
+	                           e.g. "pt-BR" instead of "pt-rBR" (xml2csv only,
+	                           csv2xml accepts both forms)
+	--sort                   - sort strings alphabetically instead of keeping
+	                           the order of source files
+	--placeholders           - replace <xliff:g> spans with tokens like "{count}",
+	                           original spans are kept in the "@placeholders"
+	                           column and restored by csv2xml (xml2csv only)
+	--xliff-version          - version of written XLIFF files, "1.2" or "2.0"
+	                           (default "1.2", xml2xliff only)
+	--source-lang            - BCP-47 tag of the base language in new string
+	                           catalogs and ARB files (default is the base
+	                           language if it is a locale or "en")
+	--include-fuzzy          - import translations marked as fuzzy, they are
+	                           marked as needing review (po2xml only)
+	--raw                    - keep android escape sequences and quotes in values
+	                           instead of showing plain text in csv file
 
-From - path to the "res" folder in your android project
+From - path to the "res" folder in your android project (or to the project
+	itself with --project)
 
-To - where to put the output (csv file in case of "xml2csv", 
-	folders with "values-xx" in case of "csv2xml")
+To - where to put the output (csv file in case of "xml2csv", folder with
+	XLIFF files in case of "xml2xliff", folder with "xx.po" files in case
+	of "xml2po", folder with ARB files in case of "xml2arb", folder with
+	"xx.lproj" folders in case of "xml2ios", folders with "values-xx" in
+	case of "csv2xml", "xliff2xml", "po2xml", "arb2xml" and "ios2xml")
 `
 )
 
 // just print help
 func help() {
-	fmt.Println(helpString)
+	fmt.Print(helpString)
+}
+
+// options defines command line options of asc
+type options struct {
+	xml.Options
+	sortCodes bool // whether to sort strings alphabetically
+	project   bool // whether to convert all "res" folders of android project
+	bcp47     bool // whether to write language codes as BCP-47 tags
+	protect   bool // whether to replace <xliff:g> spans with tokens
+	fuzzy     bool // whether to import fuzzy translations of PO files
+
+	xliffVersion string // version of written XLIFF files
+	sourceLang   string // BCP-47 tag of the base language in string catalogs
+}
+
+// csvOptions returns options of writing and reading csv file
+func (o options) csvOptions() csv.Options {
+	return csv.Options{BaseLanguage: o.BaseLanguage, BCP47: o.bcp47}
+}
+
+// xliffOptions returns options of writing and reading XLIFF files
+func (o options) xliffOptions() xliff.Options {
+	return xliff.Options{BaseLanguage: o.BaseLanguage, Version: o.xliffVersion}
+}
+
+// poOptions returns options of writing and reading PO files
+func (o options) poOptions() po.Options {
+	return po.Options{BaseLanguage: o.BaseLanguage, IncludeFuzzy: o.fuzzy}
+}
+
+// arbOptions returns options of writing and reading ARB files
+func (o options) arbOptions() arb.Options {
+	return arb.Options{BaseLanguage: o.BaseLanguage, SourceLanguage: o.sourceLang}
+}
+
+// iosOptions returns options of writing and reading iOS localization folders
+func (o options) iosOptions() ios.Options {
+	return ios.Options{BaseLanguage: o.BaseLanguage, SourceLanguage: o.sourceLang}
+}
+
+// sortDictionaries sorts codes of all given dictionaries if it is required by options
+func sortDictionaries(dicts general.Dictionaries, opts options) {
+	if !opts.sortCodes {
+		return
+	}
+	for _, d := range dicts {
+		d.Sort()
+	}
+}
+
+// fail prints the given error to stderr and exits with non-zero status
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "asc: %v\n", err)
+	os.Exit(1)
+}
+
+// readRes reads all strings from the "res" folder or from the android project at the given path
+func readRes(from string, opts options) (dicts general.Dictionaries, err error) {
+	read := xml.ReadResFolder
+	if opts.project {
+		read = xml.ReadProject
+	}
+
+	dicts, err = read(from, opts.Options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read res folder %s: %v", from, err)
+	}
+	sortDictionaries(dicts, opts)
+	return dicts, nil
+}
+
+// writeRes writes all translations to the "res" folder or to the android project at the given path
+func writeRes(to string, dicts general.Dictionaries, opts options) error {
+	write := xml.WriteResFolder
+	if opts.project {
+		write = xml.WriteProject
+	}
+
+	files, err := write(to, dicts, opts.Options)
+	for _, file := range files {
+		if file != nil {
+			file.Close()
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write res folder %s: %v", to, err)
+	}
+	return nil
+}
+
+// xmlToCSV reads the "res" folder at the given path and writes all found strings to the csv file
+func xmlToCSV(from string, to string, opts options) error {
+	dicts, err := readRes(from, opts)
+	if err != nil {
+		return err
+	}
+	if opts.protect {
+		dicts = xml.ProtectPlaceholders(dicts, opts.BaseLanguage)
+	}
+
+	return writeCSV(to, dicts, opts)
+}
+
+// readCSV reads all translations from the csv file at the given path and restores placeholders
+func readCSV(from string, opts options) (dicts general.Dictionaries, err error) {
+	dicts, err = csv.ReadCSVFile(from, opts.csvOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read csv file %s: %v", from, err)
+	}
+	sortDictionaries(dicts, opts)
+	dicts, err = xml.RestorePlaceholders(dicts, opts.BaseLanguage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to restore placeholders of csv file %s: %v", from, err)
+	}
+	return dicts, nil
+}
+
+// writeCSV writes all translations to the csv file at the given path
+func writeCSV(to string, dicts general.Dictionaries, opts options) error {
+	file, err := csv.WriteCSVFile(to, dicts, opts.csvOptions())
+	if file != nil {
+		defer file.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write csv file %s: %v", to, err)
+	}
+	return nil
+}
+
+// validate reads strings from the "res" folder or from the csv file at the given path and
+// reports translations with format specifiers, that differ from strings of the base language
+func validate(from string, opts options) error {
+	read := readRes
+	if filepath.Ext(from) == ".csv" {
+		read = readCSV
+	}
+	dicts, err := read(from, opts)
+	if err != nil {
+		return err
+	}
+
+	problems := general.ValidateFormats(dicts, opts.BaseLanguage)
+	for _, problem := range problems {
+		fmt.Println(problem)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("found %d problem(s) with format specifiers", len(problems))
+	}
+	return nil
+}
+
+// csvToXML reads the csv file at the given path and writes all translations to the "res" folder
+func csvToXML(from string, to string, opts options) error {
+	dicts, err := readCSV(from, opts)
+	if err != nil {
+		return err
+	}
+	return writeRes(to, dicts, opts)
+}
+
+// toXCStrings reads strings with the given function and writes them to Xcode string catalog
+func toXCStrings(read func(string, options) (general.Dictionaries, error)) func(string, string, options) error {
+	return func(from string, to string, opts options) error {
+		dicts, err := read(from, opts)
+		if err != nil {
+			return err
+		}
+
+		file, err := ios.WriteXCStringsFile(to, dicts, opts.iosOptions())
+		if file != nil {
+			defer file.Close()
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write string catalog %s: %v", to, err)
+		}
+		return nil
+	}
+}
+
+// fromXCStrings reads Xcode string catalog and writes its strings with the given function
+func fromXCStrings(write func(string, general.Dictionaries, options) error) func(string, string, options) error {
+	return func(from string, to string, opts options) error {
+		dicts, err := ios.ReadXCStringsFile(from, opts.iosOptions())
+		if err != nil {
+			return fmt.Errorf("failed to read string catalog %s: %v", from, err)
+		}
+		sortDictionaries(dicts, opts)
+		return write(to, dicts, opts)
+	}
+}
+
+// xmlToXLIFF reads the "res" folder at the given path and writes XLIFF file for each language to the folder
+func xmlToXLIFF(from string, to string, opts options) error {
+	dicts, err := readRes(from, opts)
+	if err != nil {
+		return err
+	}
+
+	files, err := xliff.WriteXLIFFFolder(to, dicts, opts.xliffOptions())
+	for _, file := range files {
+		file.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write xliff files to %s: %v", to, err)
+	}
+	return nil
+}
+
+// xmlToPO reads the "res" folder at the given path and writes .pot template and .po file
+// for each language to the folder
+func xmlToPO(from string, to string, opts options) error {
+	dicts, err := readRes(from, opts)
+	if err != nil {
+		return err
+	}
+
+	files, err := po.WritePOFolder(to, dicts, opts.poOptions())
+	for _, file := range files {
+		file.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write po files to %s: %v", to, err)
+	}
+	return nil
+}
+
+// poToXML reads all .po files in the folder at the given path and writes translations to the "res" folder
+func poToXML(from string, to string, opts options) error {
+	dicts, err := po.ReadPOFolder(from, opts.poOptions())
+	if err != nil {
+		return fmt.Errorf("failed to read po files from %s: %v", from, err)
+	}
+	sortDictionaries(dicts, opts)
+	return writeRes(to, dicts, opts)
+}
+
+// xmlToARB reads the "res" folder at the given path and writes ARB file for each language to the folder
+func xmlToARB(from string, to string, opts options) error {
+	dicts, err := readRes(from, opts)
+	if err != nil {
+		return err
+	}
+
+	files, err := arb.WriteARBFolder(to, dicts, opts.arbOptions())
+	for _, file := range files {
+		file.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write arb files to %s: %v", to, err)
+	}
+	return nil
+}
+
+// arbToXML reads all ARB files in the folder at the given path and writes translations to the "res" folder,
+// in merge mode existing values are kept if they differ only in <xliff:g> tags and format specifiers
+func arbToXML(from string, to string, opts options) error {
+	dicts, err := arb.ReadARBFolder(from, opts.arbOptions())
+	if err != nil {
+		return fmt.Errorf("failed to read arb files from %s: %v", from, err)
+	}
+	sortDictionaries(dicts, opts)
+
+	existing, err := readExistingRes(to, opts)
+	if err != nil {
+		return err
+	}
+	arb.KeepMarkup(dicts, existing, opts.arbOptions())
+	return writeRes(to, dicts, opts)
+}
+
+// xmlToIOS reads the "res" folder at the given path and writes all found strings to "xx.lproj" folders
+func xmlToIOS(from string, to string, opts options) error {
+	dicts, err := readRes(from, opts)
+	if err != nil {
+		return err
+	}
+
+	files, err := ios.WriteIOSFolder(to, dicts, opts.iosOptions())
+	for _, file := range files {
+		file.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write ios strings to %s: %v", to, err)
+	}
+	return nil
+}
+
+// iosToXML reads "xx.lproj" folders at the given path and writes all translations to the "res" folder
+func iosToXML(from string, to string, opts options) error {
+	dicts, err := ios.ReadIOSFolder(from, opts.iosOptions())
+	if err != nil {
+		return fmt.Errorf("failed to read ios strings from %s: %v", from, err)
+	}
+	sortDictionaries(dicts, opts)
+	return writeIOSRes(to, dicts, opts)
+}
+
+// writeIOSRes writes strings read from iOS formats to the "res" folder, in merge mode existing
+// values are kept if they differ only in <xliff:g> tags, that are removed on export to iOS
+func writeIOSRes(to string, dicts general.Dictionaries, opts options) error {
+	existing, err := readExistingRes(to, opts)
+	if err != nil {
+		return err
+	}
+	ios.KeepMarkup(dicts, existing)
+	return writeRes(to, dicts, opts)
+}
+
+// readExistingRes reads strings of the "res" folder or of the android project at the given path
+// including untranslatable ones in merge mode, so strings read from other formats can keep their
+// markup, the set is nil if the merge mode is off or there is nothing to merge into
+func readExistingRes(to string, opts options) (existing general.Dictionaries, err error) {
+	if _, err = os.Stat(to); !opts.Merge || err != nil {
+		return nil, nil
+	}
+	existingOpts := opts
+	existingOpts.IncludeUntranslatable = true
+	return readRes(to, existingOpts)
+}
+
+// xliffToXML reads all XLIFF files in the folder at the given path and writes translations to the "res" folder
+func xliffToXML(from string, to string, opts options) error {
+	dicts, err := xliff.ReadXLIFFFolder(from, opts.xliffOptions())
+	if err != nil {
+		return fmt.Errorf("failed to read xliff files from %s: %v", from, err)
+	}
+	sortDictionaries(dicts, opts)
+	return writeRes(to, dicts, opts)
 }
 
 func main() {
-	if len(os.Args) < 4 || os.Args[1] == "help" {
+	if len(os.Args) < 2 || os.Args[1] == "help" {
+		help()
+		return
+	}
+
+	command := os.Args[1]
+
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.Usage = help
+	opts := options{}
+	flags.StringVar(&opts.BaseLanguage, "base-lang", general.DefaultBaseLanguage, "")
+	flags.BoolVar(&opts.IncludeUntranslatable, "include-untranslatable", false, "")
+	flags.BoolVar(&opts.Merge, "merge", false, "")
+	flags.BoolVar(&opts.sortCodes, "sort", false, "")
+	flags.BoolVar(&opts.project, "project", false, "")
+	flags.BoolVar(&opts.bcp47, "bcp47", false, "")
+	flags.BoolVar(&opts.Raw, "raw", false, "")
+	flags.BoolVar(&opts.protect, "placeholders", false, "")
+	flags.StringVar(&opts.xliffVersion, "xliff-version", xliff.Version12, "")
+	flags.StringVar(&opts.sourceLang, "source-lang", "", "")
+	flags.BoolVar(&opts.fuzzy, "include-fuzzy", false, "")
+	if err := flags.Parse(os.Args[2:]); err != nil {
+		os.Exit(2)
+	}
+
+	if command == "validate" {
+		if flags.NArg() < 1 {
+			help()
+			return
+		}
+		if err := validate(flags.Arg(0), opts); err != nil {
+			fail(err)
+		}
+		return
+	}
+
+	if flags.NArg() < 2 {
 		help()
 		return
 	}
 
 	var (
-		command string = os.Args[1]
-		from    string = os.Args[2]
-		to      string = os.Args[3]
+		from string = flags.Arg(0)
+		to   string = flags.Arg(1)
 	)
 
 	switch command {
 	case "xml2csv":
-		res, err := xml.ReadXMLFile(from)
-		if err != nil {
-			panic(err)
+		if err := xmlToCSV(from, to, opts); err != nil {
+			fail(err)
 		}
-		r, _ := json.Marshal(res)
-		fmt.Println(string(r))
 	case "csv2xml":
-		fmt.Println(to)
+		if err := csvToXML(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "xml2xliff":
+		if err := xmlToXLIFF(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "xliff2xml":
+		if err := xliffToXML(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "xml2po":
+		if err := xmlToPO(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "po2xml":
+		if err := poToXML(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "xml2arb":
+		if err := xmlToARB(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "arb2xml":
+		if err := arbToXML(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "xml2xcstrings":
+		if err := toXCStrings(readRes)(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "csv2xcstrings":
+		if err := toXCStrings(readCSV)(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "xcstrings2xml":
+		if err := fromXCStrings(writeIOSRes)(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "xcstrings2csv":
+		if err := fromXCStrings(writeCSV)(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "xml2ios":
+		if err := xmlToIOS(from, to, opts); err != nil {
+			fail(err)
+		}
+	case "ios2xml":
+		if err := iosToXML(from, to, opts); err != nil {
+			fail(err)
+		}
 	default:
 		help()
 		return